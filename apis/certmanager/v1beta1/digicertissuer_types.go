@@ -34,6 +34,158 @@ type DigicertIssuerSpec struct {
 
 	// Provisioner contains the DigiCert provisioner configuration.
 	Provisioner DigicertProvisioner `json:"provisioner"`
+
+	// TrustAnchors publishes the roots and intermediates of the certificates issued by this issuer.
+	// +optional
+	TrustAnchors *TrustAnchors `json:"trustAnchors,omitempty"`
+
+	// ChainPolicy controls the certificate chain set in CertificateRequests issued by this issuer.
+	// +optional
+	ChainPolicy *ChainPolicy `json:"chainPolicy,omitempty"`
+
+	// RequesterPolicy restricts the users, groups and service accounts allowed to request certificates from this issuer.
+	// All requesters are allowed if not set.
+	// +optional
+	RequesterPolicy *RequesterPolicy `json:"requesterPolicy,omitempty"`
+
+	// Quota limits the number of orders and the estimated spend of this issuer within a rolling window.
+	// +optional
+	Quota *IssuanceQuota `json:"quota,omitempty"`
+
+	// Suspend stops placing new orders without deleting the issuer. Certificates of already placed orders are still
+	// downloaded. New CertificateRequests stay pending until the issuer is resumed.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// IssuanceWindows restricts placing new orders to recurring time windows. Orders are placed at any time if empty.
+	// +optional
+	IssuanceWindows []IssuanceWindow `json:"issuanceWindows,omitempty"`
+
+	// Polling configures how often the certificates of pending orders are downloaded.
+	// +optional
+	Polling *PollingPolicy `json:"polling,omitempty"`
+}
+
+// PollingPolicy configures the exponential backoff of polling pending orders. The interval starts at InitialInterval
+// and is multiplied by Factor after each attempt up to MaxInterval.
+type PollingPolicy struct {
+	// InitialInterval is the interval between placing the order and the first poll. Defaults to 1m.
+	// +optional
+	InitialInterval *metav1.Duration `json:"initialInterval,omitempty"`
+
+	// MaxInterval is the maximum interval between two polls. Defaults to 6h.
+	// +optional
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+
+	// Factor the interval is multiplied by after each attempt. Defaults to 2.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Factor *int `json:"factor,omitempty"`
+}
+
+// IssuanceWindow is a recurring time window new orders are placed in.
+type IssuanceWindow struct {
+	// Schedule is a cron expression of the start of the window, e.g. "0 8 * * 1-5" for 8:00 on weekdays.
+	Schedule string `json:"schedule"`
+
+	// Duration of the window.
+	Duration metav1.Duration `json:"duration"`
+
+	// TimeZone of the schedule as IANA time zone name. Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// IssuanceQuota limits the orders placed by an issuer within a rolling window. CertificateRequests exceeding the
+// quota stay pending until the window has room for another order. Limits not set are unlimited.
+type IssuanceQuota struct {
+	// Window is the duration of the rolling window. The usage is tracked per hour. Defaults to 24h.
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// MaxOrders is the maximum number of orders placed by the issuer within the window.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxOrders *int `json:"maxOrders,omitempty"`
+
+	// MaxOrdersPerNamespace is the maximum number of orders for CertificateRequests of a single namespace within the window.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxOrdersPerNamespace *int `json:"maxOrdersPerNamespace,omitempty"`
+
+	// MaxSpend is the maximum estimated spend of the issuer within the window as decimal in the currency of the account.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	MaxSpend string `json:"maxSpend,omitempty"`
+
+	// MaxSpendPerNamespace is the maximum estimated spend for CertificateRequests of a single namespace within the window.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	// +optional
+	MaxSpendPerNamespace string `json:"maxSpendPerNamespace,omitempty"`
+
+	// Prices maps order types to the price of an order as decimal. The price returned by CertCentral takes precedence.
+	// Used to estimate if the next order exceeds the spend limits.
+	// +optional
+	Prices map[string]string `json:"prices,omitempty"`
+}
+
+// RequesterPolicy lists the subjects allowed to request certificates. CertificateRequests created by other identities
+// are denied. The requester is the creator of the CertificateRequest as recorded by cert-manager in spec.username and
+// spec.groups, which is the cert-manager service account for CertificateRequests created for Certificates.
+type RequesterPolicy struct {
+	// Subjects allowed to request certificates.
+	Subjects []RequesterSubject `json:"subjects"`
+}
+
+// RequesterSubject is a user, group or service account allowed to request certificates.
+type RequesterSubject struct {
+	// Kind of the subject.
+	// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+	Kind string `json:"kind"`
+
+	// Name of the user, group or service account.
+	Name string `json:"name"`
+
+	// Namespace of the service account.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Domains restricts the names the subject may request certificates for.
+	// A leading wildcard like *.example.com matches all subdomains. All names are allowed if empty.
+	// +optional
+	Domains []string `json:"domains,omitempty"`
+}
+
+// ChainPolicy controls which certificates of the chain are set in the CertificateRequest.
+type ChainPolicy struct {
+	// IncludeRootInCA sets the root certificate as CA of the CertificateRequest.
+	// Defaults to true unless the --disable-root-ca flag is set.
+	// +optional
+	IncludeRootInCA *bool `json:"includeRootInCA,omitempty"`
+
+	// AppendRootToChain appends the root certificate to the certificate chain. Defaults to false.
+	// +optional
+	AppendRootToChain *bool `json:"appendRootToChain,omitempty"`
+
+	// IncludeCrossSigned includes cross-signed certificates not on the path from the certificate to its root in the
+	// certificate chain. Defaults to true.
+	// +optional
+	IncludeCrossSigned *bool `json:"includeCrossSigned,omitempty"`
+}
+
+// TrustAnchors configures publishing the distinct roots and intermediates of issued certificates to a ConfigMap.
+// The ConfigMap contains the keys ca-bundle.pem, roots.pem, intermediates.pem and the binary key ca-bundle.p7b.
+type TrustAnchors struct {
+	// ConfigMapName is the name of the ConfigMap in the namespace of the DigicertIssuer or the cluster issuer
+	// namespace for ClusterDigicertIssuers. Defaults to <issuer name>-trust-anchors.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// TrustManagerBundle is the name of a trust-manager Bundle the ConfigMap is added to as source.
+	// The Bundle is created if it does not exist. The ConfigMap is only added if the issuer publishes it to the
+	// trust namespace of trust-manager configured with --trust-namespace.
+	// +optional
+	TrustManagerBundle string `json:"trustManagerBundle,omitempty"`
 }
 
 // +kubebuilder:validation:XValidation:message="only one of validityDays and validityYears can be set.",rule="has(self.validityDays) && !has(self.validityYears) || !has(self.validityDays) && has(self.validityYears)"
@@ -47,9 +199,26 @@ type DigicertProvisioner struct {
 	// This is best-effort and falls back to the default chain when not available.
 	PreferredChain string `json:"preferredChain,omitempty"`
 
+	// PreferredChains is an ordered list of preferred trust chains. The first preference matching the root of a
+	// chain verifying the certificate is used. Falls back to the default chain if none matches.
+	// Can not be combined with preferredChain.
+	// +optional
+	PreferredChains []ChainPreference `json:"preferredChains,omitempty"`
+
 	// CACertID is the ID of the CA if multiple CA certificates are configured in the (sub-)account.
 	CACertID string `json:"caCertID,omitempty"`
 
+	// AllowedIssuerFingerprints is a list of hex encoded SHA-256 fingerprints of the intermediate and root certificates
+	// issued certificate chains may contain. Chains containing any other CA certificate are rejected.
+	// All CA certificates are allowed if empty.
+	// +optional
+	AllowedIssuerFingerprints []string `json:"allowedIssuerFingerprints,omitempty"`
+
+	// CACertIDsByKey selects the ID of the CA by the public key of the certificate request.
+	// The first matching entry is used. Requests with keys not matching any entry fail, CACertID is ignored if set.
+	// +optional
+	CACertIDsByKey []CACertIDByKey `json:"caCertIDsByKey,omitempty"`
+
 	// OrganizationID is the ID of the organization in Digicert.
 	OrganizationID *int `json:"organizationID,omitempty"`
 
@@ -79,8 +248,102 @@ type DigicertProvisioner struct {
 	// OrderType is the certificate order type.
 	OrderType string `json:"orderType,omitempty"`
 
+	// SignatureHash is the signature hash of the certificate, one of sha256, sha384, sha512 or auto.
+	// auto selects sha384 for P-384 and sha512 for P-521 ECDSA keys and sha256 otherwise. Defaults to sha256.
+	// +optional
+	SignatureHash string `json:"signatureHash,omitempty"`
+
+	// ServerPlatform is the server platform the certificate is installed on, one of nginx or other. Defaults to nginx.
+	// +optional
+	ServerPlatform string `json:"serverPlatform,omitempty"`
+
 	// ContainerID is the ID of the division
 	ContainerID *int `json:"containerID,omitempty"`
+
+	// ContainerSelector routes orders to divisions based on a label of the CertificateRequest's namespace.
+	// Orders from namespaces without a matching label value are placed in the division given by ContainerID.
+	// +optional
+	ContainerSelector *ContainerSelector `json:"containerSelector,omitempty"`
+
+	// OrderComments is a template for the comments submitted with each order.
+	// See CustomField for the available template data.
+	// +optional
+	OrderComments string `json:"orderComments,omitempty"`
+
+	// CustomFields are the custom order fields submitted with each order.
+	// +optional
+	CustomFields []CustomField `json:"customFields,omitempty"`
+
+	// ValidateCustomFields fetches the custom order fields of the account when the issuer is reconciled
+	// and reports unknown fields or missing required fields as configuration error.
+	// +optional
+	ValidateCustomFields *bool `json:"validateCustomFields,omitempty"`
+
+	// AdditionalEmails is the list of additional email addresses receiving notifications about the order,
+	// including renewal notifications unless disabled via DisableRenewalNotifications.
+	// +optional
+	AdditionalEmails []string `json:"additionalEmails,omitempty"`
+}
+
+// CustomField is a custom order field configured in the DigiCert account.
+// Values are Go templates rendered with the fields .ClusterName, .Namespace, .CertificateName,
+// .RequestName, .RequestUID and .Annotations of the CertificateRequest.
+type CustomField struct {
+	// MetadataID is the ID of the custom order field.
+	MetadataID int `json:"metadataID"`
+
+	// Value is a template for the value of the custom order field.
+	Value string `json:"value"`
+}
+
+// ContainerSelector maps namespace label values to DigiCert divisions.
+type ContainerSelector struct {
+	// NamespaceLabel is the key of the namespace label whose value selects the division.
+	NamespaceLabel string `json:"namespaceLabel"`
+
+	// Containers maps namespace label values to container IDs.
+	// +optional
+	Containers map[string]int `json:"containers,omitempty"`
+
+	// ConfigMapReference references a config map in the same namespace mapping namespace label values to container IDs.
+	// Entries in Containers take precedence.
+	// +optional
+	ConfigMapReference *ConfigMapReference `json:"configMapReference,omitempty"`
+}
+
+// ConfigMapReference references a config map in the same namespace.
+type ConfigMapReference struct {
+	// The name of the config map.
+	Name string `json:"name"`
+}
+
+// CACertIDByKey maps a public key algorithm and size to the ID of a CA.
+type CACertIDByKey struct {
+	// KeyAlgorithm is the public key algorithm of the certificate request, one of RSA, ECDSA or Ed25519.
+	KeyAlgorithm string `json:"keyAlgorithm"`
+
+	// KeySize is the size of the public key in bits, e.g. 2048 for RSA or 384 for ECDSA P-384 keys.
+	// Matches keys of any size if not set.
+	// +optional
+	KeySize *int `json:"keySize,omitempty"`
+
+	// CACertID is the ID of the CA used for matching certificate requests.
+	CACertID string `json:"caCertID"`
+}
+
+// ChainPreference matches the root of a trust chain. Exactly one of the fields must be set.
+type ChainPreference struct {
+	// RootCommonName is the common name of the root certificate.
+	// +optional
+	RootCommonName string `json:"rootCommonName,omitempty"`
+
+	// SPKIFingerprint is the hex encoded SHA-256 fingerprint of the subject public key info of the root certificate.
+	// +optional
+	SPKIFingerprint string `json:"spkiFingerprint,omitempty"`
+
+	// CertificateFingerprint is the hex encoded SHA-256 fingerprint of the root certificate.
+	// +optional
+	CertificateFingerprint string `json:"certificateFingerprint,omitempty"`
 }
 
 // SecretKeySelector references a secret in the same namespace containing sensitive configuration.
@@ -97,11 +360,51 @@ type DigicertIssuerStatus struct {
 	// Conditions is a list of DigicertIssuerConditions describing the current status.
 	// +optional
 	Conditions []DigicertIssuerCondition `json:"conditions,omitempty"`
+
+	// Intermediates lists the intermediate certificates issued certificates were signed by.
+	// +optional
+	Intermediates []IssuerIntermediate `json:"intermediates,omitempty"`
+
+	// QuotaUsage lists the orders and the spend per hour and namespace within the quota window.
+	// +optional
+	QuotaUsage []QuotaUsage `json:"quotaUsage,omitempty"`
+}
+
+// QuotaUsage is the number of orders and the spend for CertificateRequests of a namespace within an hour.
+type QuotaUsage struct {
+	// Start of the hour.
+	Start metav1.Time `json:"start"`
+
+	// Namespace of the CertificateRequests.
+	Namespace string `json:"namespace"`
+
+	// Orders placed within the hour.
+	Orders int `json:"orders"`
+
+	// Spend is the sum of the prices of the orders as decimal.
+	// +optional
+	Spend string `json:"spend,omitempty"`
+}
+
+// IssuerIntermediate is an intermediate certificate issued certificates were signed by.
+type IssuerIntermediate struct {
+	// CommonName of the intermediate certificate.
+	CommonName string `json:"commonName"`
+
+	// Fingerprint is the hex encoded SHA-256 fingerprint of the intermediate certificate.
+	Fingerprint string `json:"fingerprint"`
+
+	// FirstSeen is the time the intermediate was first seen in an issued certificate chain.
+	FirstSeen metav1.Time `json:"firstSeen"`
+
+	// Current is true for the intermediate of the most recently issued certificate.
+	// +optional
+	Current bool `json:"current,omitempty"`
 }
 
 // DigicertIssuerCondition  ...
 type DigicertIssuerCondition struct {
-	// Type of the condition, currently ('Ready').
+	// Type of the condition, one of ('Ready', 'ConfigurationError', 'Suspended').
 	Type ConditionType `json:"type"`
 
 	// Status of the condition, one of ('True', 'False', 'Unknown').
@@ -125,7 +428,7 @@ type DigicertIssuerCondition struct {
 }
 
 // ConditionType represents a DigicertIssuer condition type.
-// +kubebuilder:validation:Enum=Ready
+// +kubebuilder:validation:Enum=Ready;ConfigurationError;Suspended
 type ConditionType string
 
 const (
@@ -135,6 +438,10 @@ const (
 	// ConditionConfigurationError indicates any configuration error.
 	// See the condition message for details.
 	ConditionConfigurationError ConditionType = "ConfigurationError"
+
+	// ConditionSuspended indicates that the DigicertIssuer does not place new orders,
+	// because it is suspended or outside its issuance windows.
+	ConditionSuspended ConditionType = "Suspended"
 )
 
 // ConditionStatus represents a condition's status.
@@ -160,8 +467,12 @@ const (
 type ConditionReason string
 
 const (
-	ConditionReasonInvalidIssuerSpec     ConditionReason = "InvalidIssuerSpec"
-	ConditionReasonSecretNotFoundOrEmpty ConditionReason = "SecretNotFoundOrEmpty"
+	ConditionReasonInvalidIssuerSpec          ConditionReason = "InvalidIssuerSpec"
+	ConditionReasonSecretNotFoundOrEmpty      ConditionReason = "SecretNotFoundOrEmpty"
+	ConditionReasonConfigMapNotFoundOrInvalid ConditionReason = "ConfigMapNotFoundOrInvalid"
+	ConditionReasonInvalidCustomFields        ConditionReason = "InvalidCustomFields"
+	ConditionReasonSuspended                  ConditionReason = "Suspended"
+	ConditionReasonOutsideIssuanceWindow      ConditionReason = "OutsideIssuanceWindow"
 )
 
 // +kubebuilder:object:root=true
@@ -24,9 +24,75 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CACertIDByKey) DeepCopyInto(out *CACertIDByKey) {
+	*out = *in
+	if in.KeySize != nil {
+		in, out := &in.KeySize, &out.KeySize
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CACertIDByKey.
+func (in *CACertIDByKey) DeepCopy() *CACertIDByKey {
+	if in == nil {
+		return nil
+	}
+	out := new(CACertIDByKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainPolicy) DeepCopyInto(out *ChainPolicy) {
+	*out = *in
+	if in.IncludeRootInCA != nil {
+		in, out := &in.IncludeRootInCA, &out.IncludeRootInCA
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AppendRootToChain != nil {
+		in, out := &in.AppendRootToChain, &out.AppendRootToChain
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IncludeCrossSigned != nil {
+		in, out := &in.IncludeCrossSigned, &out.IncludeCrossSigned
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainPolicy.
+func (in *ChainPolicy) DeepCopy() *ChainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainPreference) DeepCopyInto(out *ChainPreference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainPreference.
+func (in *ChainPreference) DeepCopy() *ChainPreference {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainPreference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterDigicertIssuer) DeepCopyInto(out *ClusterDigicertIssuer) {
 	*out = *in
@@ -90,6 +156,63 @@ func (in *ClusterDigicertIssuerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerSelector) DeepCopyInto(out *ContainerSelector) {
+	*out = *in
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConfigMapReference != nil {
+		in, out := &in.ConfigMapReference, &out.ConfigMapReference
+		*out = new(ConfigMapReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerSelector.
+func (in *ContainerSelector) DeepCopy() *ContainerSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomField) DeepCopyInto(out *CustomField) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomField.
+func (in *CustomField) DeepCopy() *CustomField {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomField)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DigicertIssuer) DeepCopyInto(out *DigicertIssuer) {
 	*out = *in
@@ -176,6 +299,36 @@ func (in *DigicertIssuerList) DeepCopyObject() runtime.Object {
 func (in *DigicertIssuerSpec) DeepCopyInto(out *DigicertIssuerSpec) {
 	*out = *in
 	in.Provisioner.DeepCopyInto(&out.Provisioner)
+	if in.TrustAnchors != nil {
+		in, out := &in.TrustAnchors, &out.TrustAnchors
+		*out = new(TrustAnchors)
+		**out = **in
+	}
+	if in.ChainPolicy != nil {
+		in, out := &in.ChainPolicy, &out.ChainPolicy
+		*out = new(ChainPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequesterPolicy != nil {
+		in, out := &in.RequesterPolicy, &out.RequesterPolicy
+		*out = new(RequesterPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(IssuanceQuota)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IssuanceWindows != nil {
+		in, out := &in.IssuanceWindows, &out.IssuanceWindows
+		*out = make([]IssuanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Polling != nil {
+		in, out := &in.Polling, &out.Polling
+		*out = new(PollingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DigicertIssuerSpec.
@@ -198,6 +351,20 @@ func (in *DigicertIssuerStatus) DeepCopyInto(out *DigicertIssuerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Intermediates != nil {
+		in, out := &in.Intermediates, &out.Intermediates
+		*out = make([]IssuerIntermediate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QuotaUsage != nil {
+		in, out := &in.QuotaUsage, &out.QuotaUsage
+		*out = make([]QuotaUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DigicertIssuerStatus.
@@ -214,6 +381,23 @@ func (in *DigicertIssuerStatus) DeepCopy() *DigicertIssuerStatus {
 func (in *DigicertProvisioner) DeepCopyInto(out *DigicertProvisioner) {
 	*out = *in
 	out.APITokenReference = in.APITokenReference
+	if in.PreferredChains != nil {
+		in, out := &in.PreferredChains, &out.PreferredChains
+		*out = make([]ChainPreference, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedIssuerFingerprints != nil {
+		in, out := &in.AllowedIssuerFingerprints, &out.AllowedIssuerFingerprints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CACertIDsByKey != nil {
+		in, out := &in.CACertIDsByKey, &out.CACertIDsByKey
+		*out = make([]CACertIDByKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.OrganizationID != nil {
 		in, out := &in.OrganizationID, &out.OrganizationID
 		*out = new(int)
@@ -249,6 +433,26 @@ func (in *DigicertProvisioner) DeepCopyInto(out *DigicertProvisioner) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.ContainerSelector != nil {
+		in, out := &in.ContainerSelector, &out.ContainerSelector
+		*out = new(ContainerSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomFields != nil {
+		in, out := &in.CustomFields, &out.CustomFields
+		*out = make([]CustomField, len(*in))
+		copy(*out, *in)
+	}
+	if in.ValidateCustomFields != nil {
+		in, out := &in.ValidateCustomFields, &out.ValidateCustomFields
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AdditionalEmails != nil {
+		in, out := &in.AdditionalEmails, &out.AdditionalEmails
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DigicertProvisioner.
@@ -261,6 +465,163 @@ func (in *DigicertProvisioner) DeepCopy() *DigicertProvisioner {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuanceQuota) DeepCopyInto(out *IssuanceQuota) {
+	*out = *in
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxOrders != nil {
+		in, out := &in.MaxOrders, &out.MaxOrders
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxOrdersPerNamespace != nil {
+		in, out := &in.MaxOrdersPerNamespace, &out.MaxOrdersPerNamespace
+		*out = new(int)
+		**out = **in
+	}
+	if in.Prices != nil {
+		in, out := &in.Prices, &out.Prices
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuanceQuota.
+func (in *IssuanceQuota) DeepCopy() *IssuanceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuanceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuanceWindow) DeepCopyInto(out *IssuanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuanceWindow.
+func (in *IssuanceWindow) DeepCopy() *IssuanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerIntermediate) DeepCopyInto(out *IssuerIntermediate) {
+	*out = *in
+	in.FirstSeen.DeepCopyInto(&out.FirstSeen)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerIntermediate.
+func (in *IssuerIntermediate) DeepCopy() *IssuerIntermediate {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerIntermediate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PollingPolicy) DeepCopyInto(out *PollingPolicy) {
+	*out = *in
+	if in.InitialInterval != nil {
+		in, out := &in.InitialInterval, &out.InitialInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Factor != nil {
+		in, out := &in.Factor, &out.Factor
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PollingPolicy.
+func (in *PollingPolicy) DeepCopy() *PollingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PollingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequesterPolicy) DeepCopyInto(out *RequesterPolicy) {
+	*out = *in
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]RequesterSubject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequesterPolicy.
+func (in *RequesterPolicy) DeepCopy() *RequesterPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RequesterPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequesterSubject) DeepCopyInto(out *RequesterSubject) {
+	*out = *in
+	if in.Domains != nil {
+		in, out := &in.Domains, &out.Domains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequesterSubject.
+func (in *RequesterSubject) DeepCopy() *RequesterSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(RequesterSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecretKeySelector) DeepCopyInto(out *SecretKeySelector) {
 	*out = *in
@@ -275,3 +636,18 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustAnchors) DeepCopyInto(out *TrustAnchors) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustAnchors.
+func (in *TrustAnchors) DeepCopy() *TrustAnchors {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustAnchors)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,15 +20,28 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
 	certmanagerv1beta1controller "github.com/sapcc/digicert-issuer/controllers/certmanager"
+	"github.com/sapcc/digicert-issuer/pkg/audit"
+	"github.com/sapcc/digicert-issuer/pkg/orderwebhook"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
 	"github.com/sapcc/digicert-issuer/pkg/version"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -37,7 +50,11 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	// +kubebuilder:scaffold:imports
 )
@@ -64,9 +81,23 @@ func main() {
 		printVersionAndExit                bool
 		backoffDurationProvisionerNotReady time.Duration
 		backoffDurationRequestPending      time.Duration
+		backoffDurationIssuerNotReady      time.Duration
 		cacheSyncTimeout                   time.Duration
 		clusterIssuerNamespace             string
+		trustNamespace                     string
 		disableRootCA                      bool
+		clusterName                        string
+		revocationCheckInterval            time.Duration
+		reissueRevokedCertificates         bool
+		inventoryMetricsLimit              int
+		legacyRequestMetrics               bool
+		tracingOptions                     tracing.Options
+		auditLog                           string
+		orderStatusPollInterval            time.Duration
+		orderWebhookAddr                   string
+		orderWebhookSecretFile             string
+		orderWebhookCertDir                string
+		orderWebhookInsecure               bool
 	)
 
 	logOpts := zap.Options{
@@ -85,7 +116,10 @@ func main() {
 		"The backoff duration if the provisioner is not ready.")
 
 	flag.DurationVar(&backoffDurationRequestPending, "backoff-duration-request-pending", 15*time.Minute,
-		"The backoff duration if certificate request is pending.")
+		"The backoff duration if a certificate request waits to be ordered. Pending orders are polled as configured by the issuer.")
+
+	flag.DurationVar(&backoffDurationIssuerNotReady, "backoff-duration-issuer-not-ready", 30*time.Minute,
+		"The fallback backoff duration if the issuer of a certificate request is not ready. Requests are reconciled as soon as their issuer becomes ready.")
 
 	flag.DurationVar(&cacheSyncTimeout, "cache-sync-timeout", 2*time.Minute,
 		"The timeout on waiting for cache to sync.")
@@ -93,9 +127,41 @@ func main() {
 	flag.StringVar(&clusterIssuerNamespace, "cluster-issuer-namespace", "",
 		"Namespace, from which clusterdigicertissuer secret are read for ClusterDigicertIssuers. If left empty, ClusterDigicertIssuer is not reconciled.")
 
+	flag.StringVar(&trustNamespace, "trust-namespace", certmanagerv1beta1controller.DefaultTrustNamespace,
+		"The trust namespace of trust-manager. Trust anchors are only added to trust-manager Bundles for issuers publishing to this namespace.")
+
 	flag.BoolVar(&disableRootCA, "disable-root-ca", false,
 		"Enabling this removes root CA from CertificateRequest")
 
+	flag.StringVar(&clusterName, "cluster-name", "",
+		"Name of the cluster available to order comment and custom field templates.")
+	flag.DurationVar(&revocationCheckInterval, "revocation-check-interval", 0,
+		"The interval the revocation status of issued certificates is checked in, e.g. 12h. Disabled if 0.")
+	flag.BoolVar(&reissueRevokedCertificates, "reissue-revoked-certificates", false,
+		"Enabling this triggers the reissue of revoked certificates by cert-manager.")
+	flag.IntVar(&inventoryMetricsLimit, "inventory-metrics-limit", 1000,
+		"The maximum number of certificates expiry metrics are exported for, preferring those expiring first. Set to 0 to disable.")
+	flag.BoolVar(&legacyRequestMetrics, "legacy-request-metrics", false,
+		"Enabling this exports the deprecated request metrics labelled by CertificateRequest.")
+	flag.StringVar(&tracingOptions.Endpoint, "tracing-otlp-endpoint", "",
+		"The OTLP/HTTP endpoint traces are exported to, e.g. http://otel-collector:4318. If left empty, the OTEL_EXPORTER_OTLP_ENDPOINT environment variable is used and tracing is disabled if neither is set.")
+	flag.Float64Var(&tracingOptions.SampleRatio, "tracing-sample-ratio", 1,
+		"The fraction of traces sampled.")
+	flag.StringVar(&auditLog, "audit-log", "",
+		"Destination of the audit log of orders, reissues, revocations and denials: stdout, a file path or the URL of a webhook. If left empty, no audit log is written.")
+
+	flag.DurationVar(&orderStatusPollInterval, "order-status-poll-interval", 0,
+		"The interval the orders changed in each CertCentral account are listed in to wake their pending certificate requests. Set to 0 to poll every pending order individually.")
+
+	flag.StringVar(&orderWebhookAddr, "order-webhook-addr", "",
+		"The address the endpoint receiving CertCentral order status callbacks binds to. If left empty, callbacks are not received.")
+	flag.StringVar(&orderWebhookSecretFile, "order-webhook-secret-file", "",
+		"Path of the file containing the shared secret authenticating the CertCentral order status callbacks.")
+	flag.StringVar(&orderWebhookCertDir, "order-webhook-cert-dir", "",
+		"Directory containing the tls.crt and tls.key served by the order status callback endpoint. Required unless --order-webhook-insecure is set.")
+	flag.BoolVar(&orderWebhookInsecure, "order-webhook-insecure", false,
+		"Enabling this serves the order status callback endpoint via plain HTTP, e.g. behind a TLS terminating proxy.")
+
 	flag.Parse()
 
 	if printVersionAndExit {
@@ -105,8 +171,21 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&logOpts)))
 
+	ctx := ctrl.SetupSignalHandler()
+	shutdownTracing, err := tracing.Setup(ctx, tracingOptions)
+	handleError(err, "unable to set up tracing")
+
+	auditLogger, err := audit.New(auditLog)
+	handleError(err, "unable to open audit log")
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Cache:  cache.Options{DefaultTransform: cache.TransformStripManagedFields()},
+		Cache: cache.Options{
+			DefaultTransform: cache.TransformStripManagedFields(),
+			// Only the config maps of digicert-issuer are cached instead of all config maps of the cluster.
+			ByObject: map[client.Object]cache.ByObject{
+				&core.ConfigMap{}: {Label: labels.SelectorFromSet(labels.Set{certmanagerv1beta1controller.LabelKeyConfigMap: "true"})},
+			},
+		},
 		Scheme: scheme,
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
@@ -116,30 +195,148 @@ func main() {
 	})
 	handleError(err, "unable to start manager")
 
-	err = certmanagerv1beta1controller.NewDigicertIssuerReconciler("").SetupWithManager(mgr)
+	defaultProviderNamespace := getValueFromEnvironmentOrDefault("POD_NAMESPACE", "kube-system")
+
+	// The intermediates do not depend on the issuer, so all provisioners share one chain cache.
+	chainCache := provisioners.NewChainCache(provisioners.DefaultChainCacheSize)
+
+	digicertIssuerReconciler := certmanagerv1beta1controller.NewDigicertIssuerReconciler("")
+	digicertIssuerReconciler.ChainCache = chainCache
+	err = digicertIssuerReconciler.SetupWithManager(mgr)
 	handleError(err, "unable to initialize controller", "controller", certmanagerv1beta1.DigicertIssuerKind)
 
+	trustAnchorsReconciler := certmanagerv1beta1controller.NewTrustAnchorsReconciler("")
+	trustAnchorsReconciler.DefaultProviderNamespace = defaultProviderNamespace
+	trustAnchorsReconciler.BackoffDurationProvisionerNotReady = backoffDurationProvisionerNotReady
+	trustAnchorsReconciler.TrustNamespace = trustNamespace
+	err = trustAnchorsReconciler.SetupWithManager(mgr)
+	handleError(err, "unable to initialize controller", "controller", "trustAnchors")
+
 	if clusterIssuerNamespace != "" {
-		err = certmanagerv1beta1controller.NewDigicertIssuerReconciler(clusterIssuerNamespace).SetupWithManagerClusterIssuer(mgr)
+		clusterDigicertIssuerReconciler := certmanagerv1beta1controller.NewDigicertIssuerReconciler(clusterIssuerNamespace)
+		clusterDigicertIssuerReconciler.ChainCache = chainCache
+		err = clusterDigicertIssuerReconciler.SetupWithManagerClusterIssuer(mgr)
 		handleError(err, "unable to initialize controller", "controller", certmanagerv1beta1.ClusterDigicertIssuerKind)
+
+		clusterTrustAnchorsReconciler := certmanagerv1beta1controller.NewTrustAnchorsReconciler(clusterIssuerNamespace)
+		clusterTrustAnchorsReconciler.BackoffDurationProvisionerNotReady = backoffDurationProvisionerNotReady
+		clusterTrustAnchorsReconciler.TrustNamespace = trustNamespace
+		err = clusterTrustAnchorsReconciler.SetupWithManagerClusterIssuer(mgr)
+		handleError(err, "unable to initialize controller", "controller", "clusterTrustAnchors")
+	}
+
+	var orderStatusPoller *certmanagerv1beta1controller.OrderStatusPoller
+	if orderStatusPollInterval > 0 {
+		orderStatusPoller = certmanagerv1beta1controller.NewOrderStatusPoller(mgr, orderStatusPollInterval)
+		orderStatusPoller.DefaultProviderNamespace = defaultProviderNamespace
 	}
 
 	err = (&certmanagerv1beta1controller.CertificateRequestReconciler{
 		BackoffDurationProvisionerNotReady: backoffDurationProvisionerNotReady,
 		BackoffDurationRequestPending:      backoffDurationRequestPending,
+		BackoffDurationIssuerNotReady:      backoffDurationIssuerNotReady,
 		CacheSyncTimeout:                   cacheSyncTimeout,
-		DefaultProviderNamespace:           getValueFromEnvironmentOrDefault("POD_NAMESPACE", "kube-system"),
+		DefaultProviderNamespace:           defaultProviderNamespace,
 		DisableRootCA:                      disableRootCA,
+		ClusterName:                        clusterName,
+		LegacyRequestMetrics:               legacyRequestMetrics,
+		Audit:                              auditLogger,
+		OrderStatusPoller:                  orderStatusPoller,
 	}).SetupWithManager(mgr)
 	handleError(err, "unable to initialize controller", "controller", "certificateRequest")
 
+	if revocationCheckInterval > 0 {
+		revocationReconciler := certmanagerv1beta1controller.NewRevocationReconciler(provisioners.NewRevocationChecker(nil))
+		revocationReconciler.CheckInterval = revocationCheckInterval
+		revocationReconciler.ReissueRevoked = reissueRevokedCertificates
+		revocationReconciler.Audit = auditLogger
+		err = revocationReconciler.SetupWithManager(mgr)
+		handleError(err, "unable to initialize controller", "controller", "revocation")
+	}
+
+	if orderWebhookAddr != "" {
+		err = setupOrderWebhook(ctx, mgr, orderWebhookAddr, orderWebhookSecretFile, orderWebhookCertDir, orderWebhookInsecure)
+		handleError(err, "unable to set up order webhook")
+	}
+
+	requestsCollector := certmanagerv1beta1controller.NewRequestsCollector(mgr)
+	err = metrics.Registry.Register(requestsCollector)
+	handleError(err, "unable to register request metrics")
+	err = mgr.Add(requestsCollector)
+	handleError(err, "unable to add request metrics")
+
+	if inventoryMetricsLimit > 0 {
+		inventoryCollector := certmanagerv1beta1controller.NewInventoryCollector(mgr, inventoryMetricsLimit)
+		err = metrics.Registry.Register(inventoryCollector)
+		handleError(err, "unable to register inventory metrics")
+		err = mgr.Add(inventoryCollector)
+		handleError(err, "unable to add inventory metrics")
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
-	err = mgr.Start(ctrl.SetupSignalHandler())
+	err = mgr.Start(ctx)
+	if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+		setupLog.Error(shutdownErr, "failed to flush traces")
+	}
+	if closeErr := auditLogger.Close(); closeErr != nil {
+		setupLog.Error(closeErr, "failed to close audit log")
+	}
 	handleError(err, "problem running manager")
 }
 
+// setupOrderWebhook adds the server receiving the CertCentral order status callbacks to the manager.
+// The server runs on every replica, as the callbacks are passed on by patching the CertificateRequests.
+func setupOrderWebhook(ctx context.Context, mgr ctrl.Manager, addr, secretFile, certDir string, insecure bool) error {
+	if certDir == "" && !insecure {
+		return errors.New("--order-webhook-cert-dir is required to serve the order webhook via HTTPS, set --order-webhook-insecure to serve plain HTTP")
+	}
+
+	secret, err := os.ReadFile(secretFile)
+	if err != nil {
+		return err
+	}
+
+	receiver := certmanagerv1beta1controller.NewOrderCallbackReceiver(mgr)
+	if err := receiver.SetupWithManager(ctx, mgr); err != nil {
+		return err
+	}
+	handler, err := orderwebhook.NewHandler(strings.TrimSpace(string(secret)), receiver.Notify, mgr.GetLogger().WithName("orderwebhook"))
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if certDir != "" {
+		watcher, err := certwatcher.New(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+		if err != nil {
+			return err
+		}
+		if err := mgr.Add(watcher); err != nil {
+			return err
+		}
+		listener = tls.NewListener(listener, &tls.Config{
+			GetCertificate: watcher.GetCertificate,
+			MinVersion:     tls.VersionTLS12,
+		})
+	}
+
+	shutdownTimeout := 10 * time.Second
+	return mgr.Add(&manager.Server{
+		Name: "order-webhook",
+		Server: &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		Listener:        listener,
+		ShutdownTimeout: &shutdownTimeout,
+	})
+}
+
 func handleError(err error, message string, keysAndVals ...interface{}) {
 	if err != nil {
 		setupLog.Error(err, message, keysAndVals...)
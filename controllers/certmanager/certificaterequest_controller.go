@@ -21,8 +21,11 @@ package certmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
@@ -30,16 +33,23 @@ import (
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/go-logr/logr"
 	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/audit"
 	"github.com/sapcc/digicert-issuer/pkg/k8sutils"
 	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
@@ -49,16 +59,40 @@ type CertificateRequestReconciler struct {
 	log                                logr.Logger
 	BackoffDurationProvisionerNotReady time.Duration
 	BackoffDurationRequestPending      time.Duration
+	BackoffDurationIssuerNotReady      time.Duration
 	CacheSyncTimeout                   time.Duration
 	recorder                           record.EventRecorder
 	DefaultProviderNamespace           string
 	DisableRootCA                      bool
+	ClusterName                        string
+
+	// LegacyRequestMetrics enables the counters labelled by CertificateRequest.
+	LegacyRequestMetrics bool
+
+	// Audit records every order and denial. Nothing is recorded if nil.
+	Audit *audit.Logger
+
+	// OrderStatusPoller triggers reconciles of pending CertificateRequests whose orders changed.
+	// Pending orders are polled individually as configured by the issuer if nil.
+	OrderStatusPoller *OrderStatusPoller
+
+	// quotaUsage is the issuance quota usage per issuer. It takes precedence over the issuer status,
+	// as the cache might not reflect the latest orders yet.
+	// apiReader reads from the API server instead of the cache.
+	apiReader client.Reader
+
+	quotaMu    sync.Mutex
+	quotaUsage map[types.NamespacedName][]certmanagerv1beta1.QuotaUsage
 }
 
 const (
 	annotationKeyCertificateID  = "certmanager.cloud.sap/digicert-cert-id"
+	annotationKeyContainerID    = "certmanager.cloud.sap/digicert-container-id"
 	annotationKeyDigicertIssuer = "certmanager.cloud.sap/digicert-issuer"
 	annotationKeyOrderID        = "certmanager.cloud.sap/digicert-order-id"
+	annotationKeyOrderStatus    = "certmanager.cloud.sap/digicert-order-status"
+	// annotationKeyOrderSubmitted is the time the order was placed, used to measure the issue duration.
+	annotationKeyOrderSubmitted = "certmanager.cloud.sap/digicert-order-submitted"
 )
 
 // SetupWithManager initializes the CertificateRequest controller into the
@@ -74,23 +108,50 @@ func (r *CertificateRequestReconciler) SetupWithManager(mgr ctrl.Manager) error
 	}
 
 	r.recorder = mgr.GetEventRecorderFor("certificateRequestController")
+	r.apiReader = mgr.GetAPIReader()
 	r.log = mgr.GetLogger().WithName("controllers").WithName("CertificateRequest")
 	r.Client = mgr.GetClient()
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&cmapi.CertificateRequest{}).
-		WithOptions(controller.Options{CacheSyncTimeout: r.CacheSyncTimeout}).
-		WithEventFilter(filter).
-		Complete(r)
+
+	// Enqueue the waiting CertificateRequests of an issuer once it becomes Ready.
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &cmapi.CertificateRequest{}, issuerRefIndexKey, indexCertificateRequestByIssuerRef)
+	if err != nil {
+		return err
+	}
+	issuerHandler := handler.EnqueueRequestsFromMapFunc(r.requestsForIssuer)
+
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&cmapi.CertificateRequest{}, builder.WithPredicates(filter)).
+		Watches(&certmanagerv1beta1.DigicertIssuer{}, issuerHandler, builder.WithPredicates(issuerBecameReady)).
+		Watches(&certmanagerv1beta1.ClusterDigicertIssuer{}, issuerHandler, builder.WithPredicates(issuerBecameReady)).
+		WithOptions(controller.Options{CacheSyncTimeout: r.CacheSyncTimeout})
+	if r.OrderStatusPoller != nil {
+		if err := mgr.Add(r.OrderStatusPoller); err != nil {
+			return err
+		}
+		b = b.WatchesRawSource(r.OrderStatusPoller.Source())
+	}
+	return b.Complete(r)
 }
 
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
 
 // Reconcile will read and validate a DigicertIssuer resource associated to the
 // CertificateRequest resource, and it will sign the CertificateRequest with the
 // provisioner in the DigicertIssuer.
 func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "CertificateRequestReconciler.Reconcile",
+		attribute.String("k8s.namespace.name", req.Namespace), attribute.String("certmanager.certificaterequest.name", req.Name))
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *CertificateRequestReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	span := trace.SpanFromContext(ctx)
 	log := r.log.WithValues("certificaterequest", req.NamespacedName)
+	orderChanged := r.OrderStatusPoller.TakeChanged(req.NamespacedName)
 
 	// Fetch the CertificateRequest resource being reconciled.
 	// Just ignore the request if the certificate request has been deleted.
@@ -103,6 +164,10 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 	cr := curCR.DeepCopy()
+	span.SetAttributes(tracing.AttributeCertificateRequestUID.String(string(cr.UID)))
+	if orderID := cr.GetAnnotations()[annotationKeyOrderID]; orderID != "" {
+		span.SetAttributes(tracing.AttributeOrderID.String(orderID))
+	}
 
 	// Check the CertificateRequest's issuerRef and if it does not match the api
 	// group name, log a message at a debug level and stop processing.
@@ -118,6 +183,12 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	// Failed and denied requests are terminal. The cert-manager creates a new CertificateRequest to retry.
+	if isCertificateRequestFailed(cr) {
+		log.V(4).Info("CertificateRequest has failed or was denied, skipping")
+		return ctrl.Result{}, nil
+	}
+
 	var (
 		iss              k8sutils.Issuer
 		issNamespaceName types.NamespacedName
@@ -153,9 +224,12 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		if err := r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "%s resource %s is not Ready", iss.Kind(), issNamespaceName); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: r.BackoffDurationProvisionerNotReady}, nil
+		// The request is enqueued once the issuer becomes Ready. Requeue as fallback only.
+		return ctrl.Result{RequeueAfter: r.BackoffDurationIssuerNotReady}, nil
 	}
 
+	span.SetAttributes(tracing.AttributeIssuer.String(issNamespaceName.String()))
+
 	// Load the provisioner that will sign the CertificateRequest.
 	provisioner, ok := provisioners.Load(issNamespaceName)
 	if !ok {
@@ -169,26 +243,53 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	// Download pending certificate
 	if isCertificateRequestPending(cr) {
+		// Back off polling the pending order. Other events, e.g. a resync, must not poll before the next poll is due
+		// unless the order status poller found the order changed.
+		if dueIn := provisioners.PollDueIn(cr, time.Now()); dueIn > 0 && !orderChanged {
+			return ctrl.Result{RequeueAfter: dueIn}, nil
+		}
+
 		log.V(4).Info("CertificateRequest is in pending state, trying to download certificate.", "name", cr.ObjectMeta.Name)
 		caPEM, certPEM, err := provisioner.Download(ctx, cr)
 
+		var unexpectedIssuer *provisioners.UnexpectedIssuerError
+		if errors.As(err, &unexpectedIssuer) {
+			return ctrl.Result{}, r.setUnexpectedIssuer(ctx, cr, curCR, iss, err)
+		}
 		if err != nil || len(certPEM) < 1 {
-			log.V(4).Info("Download of pending certificate failed, reqeueing.", "name", cr.ObjectMeta.Name)
+			pollInterval := provisioner.SchedulePoll(cr, time.Now(), r.OrderStatusPoller.fallbackInterval())
+			log.V(4).Info("Download of pending certificate failed, reqeueing.", "name", cr.ObjectMeta.Name, "error", err, "pollInterval", pollInterval)
+			if err := r.Client.Patch(ctx, cr, client.MergeFrom(curCR)); err != nil {
+				log.Error(err, "failed to update certificate request annotations")
+				return ctrl.Result{}, err
+			}
 			_ = r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "Certificate request pending")
-			metricRequestsPending.WithLabelValues(cr.ObjectMeta.Name,
-				cr.ObjectMeta.GetAnnotations()["cert-manager.io/certificate-name"],
-				cr.ObjectMeta.GetAnnotations()["cert-manager.io/private-key-secret-name"],
-				cr.ObjectMeta.GetAnnotations()[annotationKeyOrderID],
-			).Inc()
+			r.observeRequestPending(cr)
+
+			return ctrl.Result{RequeueAfter: pollInterval}, nil
+		}
+
+		// Persist the preferred chain selected by the provisioner.
+		if cr.GetAnnotations()[provisioners.AnnotationKeyPreferredChain] != curCR.GetAnnotations()[provisioners.AnnotationKeyPreferredChain] {
+			if err := r.Client.Patch(ctx, cr, client.MergeFrom(curCR)); err != nil {
+				log.Error(err, "failed to update certificate request annotations")
+				return ctrl.Result{}, err
+			}
+		}
 
-			return ctrl.Result{Requeue: true, RequeueAfter: r.BackoffDurationRequestPending}, err
+		if err := provisioners.VerifyCertificate(cr.Spec.Request, certPEM, caPEM, time.Now()); err != nil {
+			return ctrl.Result{}, r.setVerificationFailed(ctx, cr, curCR, err)
 		}
 
-		if len(caPEM) > 0 && !r.DisableRootCA {
+		if len(caPEM) > 0 && provisioner.IncludeRootInCA(!r.DisableRootCA) {
 			cr.Status.CA = caPEM
 		}
 		cr.Status.Certificate = certPEM
 		err = r.setStatus(ctx, cr, curCR, cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued, "Certificate issued")
+		if err == nil {
+			observeIssueDuration(cr, issNamespaceName, time.Now())
+			r.recordIntermediate(ctx, iss, issNamespaceName, certPEM)
+		}
 
 		return ctrl.Result{}, err
 	}
@@ -203,51 +304,121 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{Requeue: true, RequeueAfter: r.BackoffDurationRequestPending}, nil
 	}
 
-	// Sign CertificateRequest.
-	caPEM, certPEM, order, err := provisioner.Sign(ctx, cr)
+	// Deny requests from identities not allowed by the requester policy of the issuer.
+	// Requests that can't be authorized are failed with the actual reason instead.
+	if err := provisioner.AuthorizeRequester(cr); err != nil {
+		var requesterDenied *provisioners.RequesterDeniedError
+		if errors.As(err, &requesterDenied) {
+			return ctrl.Result{}, r.setRequesterDenied(ctx, cr, curCR, issNamespaceName, err)
+		}
+		log.Error(err, "failed to authorize requester")
+		r.observeRequestError(cr, "Invalid certificate request")
+		return ctrl.Result{}, r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "Failed to authorize requester: %v", err)
+	}
+
+	// Hold new orders while the issuer is suspended or outside its issuance windows.
+	if suspension := provisioner.Suspension(time.Now()); suspension != nil {
+		log.Info("holding new order", "issuer", issNamespaceName, "reason", suspension.Reason)
+		if err := r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "Holding new order: %s", suspension.Message); err != nil {
+			return ctrl.Result{}, err
+		}
+		requeueAfter := r.BackoffDurationRequestPending
+		if !suspension.Until.IsZero() {
+			requeueAfter = max(time.Until(suspension.Until), r.BackoffDurationProvisionerNotReady)
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// Hold the request while another order would exceed the issuance quota of the issuer.
+	var quotaExceeded *provisioners.QuotaExceededError
+	if err := provisioner.CheckQuota(r.getQuotaUsage(iss, issNamespaceName), cr.Namespace, time.Now()); errors.As(err, &quotaExceeded) {
+		return r.setQuotaExceeded(ctx, cr, curCR, issNamespaceName, quotaExceeded)
+	}
+
+	// Select the division the order is placed in.
+	containerID, err := r.getContainerID(ctx, provisioner, cr.Namespace)
 	if err != nil {
-		log.Error(err, "failed to sign certificate request")
-		metricRequestErrors.WithLabelValues(
-			cr.ObjectMeta.Name,
-			cr.ObjectMeta.GetAnnotations()["cert-manager.io/certificate-name"],
-			cr.ObjectMeta.GetAnnotations()["cert-manager.io/private-key-secret-name"],
-			"Failed to sign certificate request",
-		).Inc()
-		return ctrl.Result{}, r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "Failed to sign certificate request: %v", err)
+		log.Error(err, "failed to retrieve namespace to select division")
+		return ctrl.Result{}, err
 	}
 
+	// Sign CertificateRequest. The order is recorded as soon as it was placed, even if processing the chain fails.
+	var submittedAt time.Time
+	caPEM, certPEM, order, err := provisioner.Sign(ctx, cr, provisioners.OrderOptions{
+		ContainerID: containerID,
+		ClusterName: r.ClusterName,
+		OnSubmitted: func(order *provisioners.Order) {
+			submittedAt = time.Now()
+			r.recordOrder(ctx, cr, issNamespaceName, provisioner, containerID, order)
+			r.recordQuotaUsage(ctx, iss, issNamespaceName, provisioner, cr.Namespace, order)
+		},
+	})
+
 	// Patch annotations.
-	annotations := cr.ObjectMeta.GetAnnotations()
-	annotations[annotationKeyDigicertIssuer] = "true"
-	if order.ID > 0 {
-		annotations[annotationKeyOrderID] = fmt.Sprintf("%d", order.ID)
+	var pollInterval time.Duration
+	if order != nil {
+		annotations := cr.ObjectMeta.GetAnnotations()
+		annotations[annotationKeyDigicertIssuer] = "true"
+		if order.ID > 0 {
+			annotations[annotationKeyOrderID] = fmt.Sprintf("%d", order.ID)
+			span.SetAttributes(tracing.AttributeOrderID.String(annotations[annotationKeyOrderID]))
+		}
+		if order.CertificateID > 0 {
+			annotations[annotationKeyCertificateID] = fmt.Sprintf("%d", order.CertificateID)
+		}
+		if containerID > 0 {
+			annotations[annotationKeyContainerID] = fmt.Sprintf("%d", containerID)
+		}
+		if !submittedAt.IsZero() {
+			annotations[annotationKeyOrderSubmitted] = submittedAt.UTC().Format(time.RFC3339)
+		}
+		cr.ObjectMeta.SetAnnotations(annotations)
+		if err == nil && len(certPEM) == 0 && order.CertificateID > 0 {
+			pollInterval = provisioner.SchedulePoll(cr, time.Now(), r.OrderStatusPoller.fallbackInterval())
+		}
+		if err := r.Client.Patch(ctx, cr, client.MergeFrom(curCR)); err != nil {
+			log.Error(err, "failed to update certificate request annotations")
+			return ctrl.Result{}, err
+		}
+	}
+
+	var unexpectedIssuer *provisioners.UnexpectedIssuerError
+	if errors.As(err, &unexpectedIssuer) {
+		return ctrl.Result{}, r.setUnexpectedIssuer(ctx, cr, curCR, iss, err)
 	}
-	if order.CertificateID > 0 {
-		annotations[annotationKeyCertificateID] = fmt.Sprintf("%d", order.CertificateID)
+	var templateErr *provisioners.OrderTemplateError
+	if errors.As(err, &templateErr) {
+		log.Error(err, "failed to render order template")
+		r.observeRequestError(cr, "Failed to render order template")
+		return ctrl.Result{}, r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed,
+			"Failed to render %s of the issuer for this CertificateRequest, check the annotations it references: %v", templateErr.Template, templateErr.Err)
 	}
-	cr.ObjectMeta.SetAnnotations(annotations)
-	if err := r.Client.Patch(ctx, cr, client.MergeFrom(curCR)); err != nil {
-		log.Error(err, "failed to update certificate request annotations")
-		return ctrl.Result{}, err
+	if err != nil {
+		log.Error(err, "failed to sign certificate request")
+		r.observeRequestError(cr, "Failed to sign certificate request")
+		return ctrl.Result{}, r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "Failed to sign certificate request: %v", err)
 	}
 
 	// Update CertificateRequest status
 	if len(certPEM) > 0 {
-		if len(caPEM) > 0 && !r.DisableRootCA {
+		if err := provisioners.VerifyCertificate(cr.Spec.Request, certPEM, caPEM, time.Now()); err != nil {
+			return ctrl.Result{}, r.setVerificationFailed(ctx, cr, curCR, err)
+		}
+
+		if len(caPEM) > 0 && provisioner.IncludeRootInCA(!r.DisableRootCA) {
 			cr.Status.CA = caPEM
 		}
 		cr.Status.Certificate = certPEM
 		err = r.setStatus(ctx, cr, curCR, cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued, "Certificate issued")
+		if err == nil {
+			observeIssueDuration(cr, issNamespaceName, time.Now())
+			r.recordIntermediate(ctx, iss, issNamespaceName, certPEM)
+		}
 	} else if order.CertificateID > 0 {
 		err = r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "Certificate request pending")
-		return ctrl.Result{Requeue: true, RequeueAfter: r.BackoffDurationProvisionerNotReady}, err
+		return ctrl.Result{RequeueAfter: pollInterval}, err
 	} else {
-		metricRequestErrors.WithLabelValues(
-			cr.ObjectMeta.Name,
-			cr.ObjectMeta.GetAnnotations()["cert-manager.io/certificate-name"],
-			cr.ObjectMeta.GetAnnotations()["cert-manager.io/private-key-secret-name"],
-			"Certificate request failed",
-		).Inc()
+		r.observeRequestError(cr, "Certificate request failed")
 		err = r.setStatus(ctx, cr, curCR, cmmeta.ConditionUnknown, cmapi.CertificateRequestReasonFailed, "Certificate request failed")
 	}
 
@@ -255,6 +426,19 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 	return ctrl.Result{}, err
 }
 
+// getContainerID returns the division for orders from the given namespace.
+func (r *CertificateRequestReconciler) getContainerID(ctx context.Context, provisioner *provisioners.CertCentral, namespace string) (int, error) {
+	if provisioner.ContainerLabel() == "" {
+		return provisioner.ContainerID(nil), nil
+	}
+
+	ns := new(core.Namespace)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return 0, err
+	}
+	return provisioner.ContainerID(ns.GetLabels()), nil
+}
+
 func isDigicertIssuerReady(issuer k8sutils.Issuer) bool {
 	status := issuer.Status()
 	if status == nil {
@@ -284,6 +468,16 @@ func isCertificateRequestPending(cr *cmapi.CertificateRequest) bool {
 	return false
 }
 
+func isCertificateRequestFailed(cr *cmapi.CertificateRequest) bool {
+	for _, condition := range cr.Status.Conditions {
+		if condition.Type == cmapi.CertificateRequestConditionReady &&
+			(condition.Reason == cmapi.CertificateRequestReasonFailed || condition.Reason == cmapi.CertificateRequestReasonDenied) {
+			return true
+		}
+	}
+	return false
+}
+
 func isCertificateRequestIssued(cr *cmapi.CertificateRequest) bool {
 	status := cr.Status
 
@@ -306,10 +500,216 @@ func isCertificateRequestStatusTrue(cr *cmapi.CertificateRequest) bool {
 	return false
 }
 
+// observeIssueDuration records the duration from placing the order until the certificate was issued.
+// Requests ordered before the submission time was recorded fall back to their creation time.
+func observeIssueDuration(cr *cmapi.CertificateRequest, issNamespaceName types.NamespacedName, now time.Time) {
+	submittedAt := cr.CreationTimestamp.Time
+	if value := cr.GetAnnotations()[annotationKeyOrderSubmitted]; value != "" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			submittedAt = t
+		}
+	}
+	metricOrderIssueDuration.WithLabelValues(issNamespaceName.String()).Observe(now.Sub(submittedAt).Seconds())
+}
+
+// recordOrder counts the order and writes it to the audit log.
+func (r *CertificateRequestReconciler) recordOrder(ctx context.Context, cr *cmapi.CertificateRequest, issNamespaceName types.NamespacedName, provisioner *provisioners.CertCentral, containerID int, order *provisioners.Order) {
+	metricOrders.WithLabelValues(issNamespaceName.String(), provisioner.OrderType(), strconv.Itoa(containerID)).Inc()
+	rec := audit.ForCertificateRequest(audit.ActionOrder, cr)
+	rec.Issuer = issNamespaceName.String()
+	rec.OrderType = provisioner.OrderType()
+	rec.OrderID = order.ID
+	rec.Price = order.Price
+	rec.Currency = order.Currency
+	r.Audit.Log(ctx, rec)
+}
+
+// setVerificationFailed marks the CertificateRequest as failed because the issued certificate did not pass verification.
+func (r *CertificateRequestReconciler) setVerificationFailed(ctx context.Context, cr, curCR *cmapi.CertificateRequest, err error) error {
+	r.log.Error(err, "issued certificate failed verification", "namespace", cr.Namespace, "name", cr.Name)
+	r.observeRequestError(cr, "Certificate verification failed")
+	return r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "Issued certificate failed verification: %v", err)
+}
+
+// setUnexpectedIssuer marks the CertificateRequest as failed because the issued certificate chain contains a CA
+// certificate not allowed by the issuer. This might indicate a misconfigured caCertID or a compromised account.
+func (r *CertificateRequestReconciler) setUnexpectedIssuer(ctx context.Context, cr, curCR *cmapi.CertificateRequest, iss k8sutils.Issuer, err error) error {
+	r.log.Error(err, "issued certificate chain rejected", "namespace", cr.Namespace, "name", cr.Name)
+	r.observeRequestError(cr, "Unexpected issuer")
+	r.recorder.Eventf(iss.Object(), core.EventTypeWarning, "UnexpectedIssuer", "Rejected certificate chain for CertificateRequest %s/%s: %v", cr.Namespace, cr.Name, err)
+
+	rec := audit.ForCertificateRequest(audit.ActionDenial, cr)
+	rec.Issuer = client.ObjectKeyFromObject(iss.Object()).String()
+	rec.Reason = err.Error()
+	r.Audit.Log(ctx, rec)
+
+	return r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "Rejected certificate chain with unexpected issuer: %v", err)
+}
+
+// setRequesterDenied marks the CertificateRequest as denied because its creator is not allowed to request the
+// certificate by the requester policy of the issuer.
+func (r *CertificateRequestReconciler) setRequesterDenied(ctx context.Context, cr, curCR *cmapi.CertificateRequest, issNamespaceName types.NamespacedName, err error) error {
+	r.log.Info("certificate request denied by requester policy", "namespace", cr.Namespace, "name", cr.Name, "error", err.Error())
+	r.observeRequestError(cr, "Requester denied")
+
+	rec := audit.ForCertificateRequest(audit.ActionDenial, cr)
+	rec.Issuer = issNamespaceName.String()
+	rec.Reason = err.Error()
+	r.Audit.Log(ctx, rec)
+
+	return r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonDenied, "Denied by requester policy: %v", err)
+}
+
+// setQuotaExceeded keeps the CertificateRequest pending until the issuance quota allows another order.
+func (r *CertificateRequestReconciler) setQuotaExceeded(ctx context.Context, cr, curCR *cmapi.CertificateRequest, issNamespaceName types.NamespacedName, exceeded *provisioners.QuotaExceededError) (ctrl.Result, error) {
+	r.log.Info("issuance quota exceeded", "namespace", cr.Namespace, "name", cr.Name, "issuer", issNamespaceName, "error", exceeded.Error())
+	metricQuotaExceeded.WithLabelValues(issNamespaceName.String(), cr.Namespace, exceeded.Limit).Inc()
+
+	if err := r.setStatus(ctx, cr, curCR, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "Issuance quota exceeded: %v", exceeded); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: max(time.Until(exceeded.RetryAt), r.BackoffDurationProvisionerNotReady)}, nil
+}
+
+// getQuotaUsage returns the issuance quota usage of the issuer.
+func (r *CertificateRequestReconciler) getQuotaUsage(iss k8sutils.Issuer, issNamespaceName types.NamespacedName) []certmanagerv1beta1.QuotaUsage {
+	r.quotaMu.Lock()
+	defer r.quotaMu.Unlock()
+	return r.getQuotaUsageLocked(iss, issNamespaceName)
+}
+
+func (r *CertificateRequestReconciler) getQuotaUsageLocked(iss k8sutils.Issuer, issNamespaceName types.NamespacedName) []certmanagerv1beta1.QuotaUsage {
+	if usage, ok := r.quotaUsage[issNamespaceName]; ok {
+		return usage
+	}
+	if status := iss.Status(); status != nil {
+		return status.QuotaUsage
+	}
+	return nil
+}
+
+// recordQuotaUsage adds the order to the issuance quota usage and persists it in the issuer status, so it survives restarts.
+// Errors are only logged as the order was already placed.
+func (r *CertificateRequestReconciler) recordQuotaUsage(ctx context.Context, iss k8sutils.Issuer, issNamespaceName types.NamespacedName, provisioner *provisioners.CertCentral, namespace string, order *provisioners.Order) {
+	r.quotaMu.Lock()
+	usage, changed := provisioner.RecordQuotaUsage(r.getQuotaUsageLocked(iss, issNamespaceName), namespace, order, time.Now())
+	if changed {
+		if r.quotaUsage == nil {
+			r.quotaUsage = make(map[types.NamespacedName][]certmanagerv1beta1.QuotaUsage)
+		}
+		r.quotaUsage[issNamespaceName] = usage
+	}
+	r.quotaMu.Unlock()
+	if !changed {
+		return
+	}
+
+	newStatus := iss.Status().DeepCopy()
+	if newStatus == nil {
+		newStatus = &certmanagerv1beta1.DigicertIssuerStatus{}
+	}
+	newStatus.QuotaUsage = usage
+	if _, err := iss.PatchStatus(ctx, r.Client, newStatus); err != nil {
+		r.log.Error(err, "failed to update quota usage in issuer status", "issuer", issNamespaceName)
+	}
+}
+
+// recordIntermediate persists the intermediate of the issued certificate in the issuer status and alerts if it was rotated.
+// Errors are only logged as the certificate was already issued.
+func (r *CertificateRequestReconciler) recordIntermediate(ctx context.Context, iss k8sutils.Issuer, issNamespaceName types.NamespacedName, certPEM []byte) {
+	log := r.log.WithValues("issuer", issNamespaceName)
+
+	intermediate, err := provisioners.IssuingIntermediate(certPEM)
+	if err != nil || intermediate == nil {
+		log.V(4).Info("no intermediate found in issued certificate chain", "error", err)
+		return
+	}
+
+	// The cached issuer might not contain the intermediate recorded by a previous request yet,
+	// which would report the rotation again.
+	iss, err = r.getLatestIssuer(ctx, iss)
+	if err != nil {
+		log.Error(err, "failed to get issuer")
+		return
+	}
+
+	newStatus := iss.Status().DeepCopy()
+	if newStatus == nil {
+		newStatus = &certmanagerv1beta1.DigicertIssuerStatus{}
+	}
+	previous, changed := provisioners.RecordIntermediate(newStatus, intermediate, time.Now())
+	if !changed {
+		return
+	}
+	if _, err := iss.PatchStatus(ctx, r.Client, newStatus); err != nil {
+		log.Error(err, "failed to update intermediates in issuer status")
+		return
+	}
+
+	if previous != nil {
+		log.Info("intermediate rotated", "previous", previous.CommonName, "previousFingerprint", previous.Fingerprint,
+			"current", intermediate.Subject.CommonName)
+		metricIntermediateRotations.WithLabelValues(issNamespaceName.String()).Inc()
+		metricIntermediateRotationTimestamp.WithLabelValues(issNamespaceName.String()).SetToCurrentTime()
+		r.recorder.Eventf(iss.Object(), core.EventTypeWarning, "IntermediateRotated",
+			"Certificates are signed by intermediate %q instead of %q (SHA-256 %s)",
+			intermediate.Subject.CommonName, previous.CommonName, previous.Fingerprint)
+	}
+}
+
+// getLatestIssuer reads the issuer from the API server, bypassing the cache.
+func (r *CertificateRequestReconciler) getLatestIssuer(ctx context.Context, iss k8sutils.Issuer) (k8sutils.Issuer, error) {
+	reader := r.apiReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	latest := k8sutils.NewDigicertIssuer()
+	if iss.Kind() == certmanagerv1beta1.ClusterDigicertIssuerKind {
+		latest = k8sutils.NewClusterDigicertIssuer()
+	}
+	if err := latest.Get(ctx, reader, client.ObjectKeyFromObject(iss.Object())); err != nil {
+		return iss, err
+	}
+	return latest, nil
+}
+
+// observeRequestPending increases the legacy per-request pending counter if enabled.
+func (r *CertificateRequestReconciler) observeRequestPending(cr *cmapi.CertificateRequest) {
+	if !r.LegacyRequestMetrics {
+		return
+	}
+	metricRequestsPending.WithLabelValues(
+		cr.ObjectMeta.Name,
+		cr.ObjectMeta.GetAnnotations()["cert-manager.io/certificate-name"],
+		cr.ObjectMeta.GetAnnotations()["cert-manager.io/private-key-secret-name"],
+		cr.ObjectMeta.GetAnnotations()[annotationKeyOrderID],
+	).Inc()
+}
+
+// observeRequestError increases the legacy per-request error counter if enabled.
+func (r *CertificateRequestReconciler) observeRequestError(cr *cmapi.CertificateRequest, reason string) {
+	if !r.LegacyRequestMetrics {
+		return
+	}
+	metricRequestErrors.WithLabelValues(
+		cr.ObjectMeta.Name,
+		cr.ObjectMeta.GetAnnotations()["cert-manager.io/certificate-name"],
+		cr.ObjectMeta.GetAnnotations()["cert-manager.io/private-key-secret-name"],
+		reason,
+	).Inc()
+}
+
 func (r *CertificateRequestReconciler) setStatus(ctx context.Context, cr, curCR *cmapi.CertificateRequest, status cmmeta.ConditionStatus, reason, message string, args ...interface{}) error {
 	completeMessage := fmt.Sprintf(message, args...)
 	apiutil.SetCertificateRequestCondition(cr, cmapi.CertificateRequestConditionReady, status, reason, completeMessage)
 
+	// cert-manager uses the failure time to back off before retrying failed requests.
+	if reason == cmapi.CertificateRequestReasonFailed && cr.Status.FailureTime == nil {
+		now := metav1.NewTime(time.Now())
+		cr.Status.FailureTime = &now
+	}
+
 	// Fire an Event to additionally inform users of the change
 	eventType := core.EventTypeNormal
 	if status == cmmeta.ConditionFalse {
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/k8sutils"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObserveIssueDuration(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-48 * time.Hour))
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    float64
+	}{
+		{
+			name:        "submission time",
+			annotations: map[string]string{annotationKeyOrderSubmitted: now.Add(-10 * time.Minute).Format(time.RFC3339)},
+			expected:    600,
+		},
+		{
+			name:     "creation time without submission time",
+			expected: 48 * 3600,
+		},
+		{
+			name:        "creation time with invalid submission time",
+			annotations: map[string]string{annotationKeyOrderSubmitted: "yesterday"},
+			expected:    48 * 3600,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := types.NamespacedName{Namespace: "default", Name: "issue-duration-" + t.Name()}
+			cr := &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created, Annotations: tt.annotations}}
+			observeIssueDuration(cr, issuer, now)
+
+			m := new(dto.Metric)
+			if err := metricOrderIssueDuration.WithLabelValues(issuer.String()).(prometheus.Histogram).Write(m); err != nil {
+				t.Fatalf("failed to read histogram: %v", err)
+			}
+			if count := m.GetHistogram().GetSampleCount(); count != 1 {
+				t.Fatalf("expected 1 observation, got %d", count)
+			}
+			if sum := m.GetHistogram().GetSampleSum(); sum != tt.expected {
+				t.Fatalf("expected duration %v, got %v", tt.expected, sum)
+			}
+		})
+	}
+}
+
+func TestCertificateRequestReconcileSkipsTerminalRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		reason     string
+		expectSkip bool
+	}{
+		{name: "failed", reason: cmapi.CertificateRequestReasonFailed, expectSkip: true},
+		{name: "denied", reason: cmapi.CertificateRequestReasonDenied, expectSkip: true},
+		{name: "pending", reason: cmapi.CertificateRequestReasonPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "example"},
+				Spec: cmapi.CertificateRequestSpec{
+					IssuerRef: cmmeta.ObjectReference{
+						Group: certmanagerv1beta1.GroupVersion.Group,
+						Kind:  certmanagerv1beta1.DigicertIssuerKind,
+						Name:  "missing",
+					},
+				},
+				Status: cmapi.CertificateRequestStatus{
+					Conditions: []cmapi.CertificateRequestCondition{{
+						Type:   cmapi.CertificateRequestConditionReady,
+						Status: cmmeta.ConditionFalse,
+						Reason: tt.reason,
+					}},
+				},
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &CertificateRequestReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(cr).WithStatusSubresource(cr).Build(),
+				log:      logr.Discard(),
+				recorder: recorder,
+			}
+
+			_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team", Name: "example"}})
+			got := new(cmapi.CertificateRequest)
+			if getErr := r.Client.Get(context.Background(), types.NamespacedName{Namespace: "team", Name: "example"}, got); getErr != nil {
+				t.Fatalf("failed to get certificate request: %v", getErr)
+			}
+
+			if !tt.expectSkip {
+				// The request is processed and waits for the missing issuer.
+				if err == nil || len(recorder.Events) == 0 {
+					t.Fatalf("expected request to be processed, got error %v and %d events", err, len(recorder.Events))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reconcile returned error: %v", err)
+			}
+			if len(recorder.Events) != 0 {
+				t.Fatalf("expected no events for terminal request, got %d", len(recorder.Events))
+			}
+			if !reflect.DeepEqual(got.Status, cr.Status) {
+				t.Fatalf("expected status of terminal request to be unchanged, got %+v", got.Status)
+			}
+		})
+	}
+}
+
+func TestRecordIntermediate(t *testing.T) {
+	leafPEM, rootPEM := newTestChain(t)
+	chainPEM := append(append([]byte{}, leafPEM...), rootPEM...)
+	intermediate, err := provisioners.IssuingIntermediate(chainPEM)
+	if err != nil || intermediate == nil {
+		t.Fatalf("failed to get intermediate: %v", err)
+	}
+
+	oldStatus := &certmanagerv1beta1.DigicertIssuerStatus{
+		Intermediates: []certmanagerv1beta1.IssuerIntermediate{{CommonName: "Old CA", Fingerprint: "00", Current: true}},
+	}
+	rotatedStatus := oldStatus.DeepCopy()
+	provisioners.RecordIntermediate(rotatedStatus, intermediate, time.Now())
+
+	tests := []struct {
+		name         string
+		cachedStatus *certmanagerv1beta1.DigicertIssuerStatus
+		serverStatus *certmanagerv1beta1.DigicertIssuerStatus
+		expectEvent  bool
+	}{
+		{
+			name:         "rotation",
+			cachedStatus: oldStatus,
+			serverStatus: oldStatus,
+			expectEvent:  true,
+		},
+		{
+			name:         "rotation already recorded",
+			cachedStatus: rotatedStatus,
+			serverStatus: rotatedStatus,
+		},
+		{
+			name:         "rotation already recorded but not cached yet",
+			cachedStatus: oldStatus,
+			serverStatus: rotatedStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := &certmanagerv1beta1.DigicertIssuer{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "digicert"},
+				Status:     tt.serverStatus.DeepCopy(),
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &CertificateRequestReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(stored).WithStatusSubresource(stored).Build(),
+				log:      logr.Discard(),
+				recorder: recorder,
+			}
+
+			cached := &k8sutils.DigicertIssuer{DigicertIssuer: *stored.DeepCopy()}
+			cached.SetStatus(tt.cachedStatus.DeepCopy())
+			r.recordIntermediate(context.Background(), cached, types.NamespacedName{Namespace: "team", Name: "digicert"}, chainPEM)
+
+			if got := len(recorder.Events) > 0; got != tt.expectEvent {
+				t.Fatalf("expected rotation event: %v, got %d events", tt.expectEvent, len(recorder.Events))
+			}
+			got := new(certmanagerv1beta1.DigicertIssuer)
+			if err := r.Client.Get(context.Background(), types.NamespacedName{Namespace: "team", Name: "digicert"}, got); err != nil {
+				t.Fatalf("failed to get issuer: %v", err)
+			}
+			if len(got.Status.Intermediates) != 2 || !got.Status.Intermediates[1].Current {
+				t.Fatalf("expected the intermediate to be current, got %+v", got.Status.Intermediates)
+			}
+		})
+	}
+}
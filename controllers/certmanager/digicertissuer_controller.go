@@ -23,23 +23,44 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/hashicorp/go-multierror"
 	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
 	"github.com/sapcc/digicert-issuer/pkg/k8sutils"
 	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// LabelKeyConfigMap marks the config maps cached and watched by the controllers, i.e. those referenced by container
+// selectors and the published trust anchors. Other config maps are not cached, so not all config maps of the cluster are kept in memory.
+const LabelKeyConfigMap = "certmanager.cloud.sap/digicert-issuer-config"
+
 // DigicertIssuerReconciler reconciles a DigicertIssuer object
 type DigicertIssuerReconciler struct {
 	client.Client
 	log                    logr.Logger
 	recorder               record.EventRecorder
 	clusterIssuerNamespace string
+
+	// apiReader reads the config maps of container selectors, which are only cached if labelled with LabelKeyConfigMap.
+	apiReader client.Reader
+
+	// ChainCache is shared by the provisioners of all issuers.
+	ChainCache *provisioners.ChainCache
 }
 
 func NewDigicertIssuerReconciler(clusterIssuerNamespace string) *DigicertIssuerReconciler {
@@ -53,9 +74,17 @@ func NewDigicertIssuerReconciler(clusterIssuerNamespace string) *DigicertIssuerR
 // +kubebuilder:rbac:groups=certmanager.cloud.sap,resources=clusterdigicertissuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=certmanager.cloud.sap,resources=clusterdigicertissuers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *DigicertIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "DigicertIssuerReconciler.Reconcile", tracing.AttributeIssuer.String(req.NamespacedName.String()))
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *DigicertIssuerReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	var issuer k8sutils.Issuer
 	secretNamespace := req.Namespace
 	if req.Namespace == "" {
@@ -84,7 +113,7 @@ func (r *DigicertIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		logger.Error(err, "issuer.spec is invalid")
 		return ctrl.Result{}, err
 	}
-	k8sutils.SetDigicertIssuerStatusConditionType(
+	issuer, _ = k8sutils.SetDigicertIssuerStatusConditionType(
 		ctx, r.Client, issuer, certmanagerv1beta1.ConditionConfigurationError, certmanagerv1beta1.ConditionFalse, "", "",
 	)
 
@@ -98,31 +127,70 @@ func (r *DigicertIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		)
 		return ctrl.Result{}, err
 	}
-	k8sutils.SetDigicertIssuerStatusConditionType(
+	issuer, _ = k8sutils.SetDigicertIssuerStatusConditionType(
 		ctx, r.Client, issuer, certmanagerv1beta1.ConditionConfigurationError, certmanagerv1beta1.ConditionFalse, "", "",
 	)
 
-	prov, err := provisioners.New(fmt.Sprintf("%s/%s", req.Namespace, req.Name), issuer.Spec(), digicertAPIToken, logger, r.recorder)
+	issuerSpec, err := r.resolveContainerSelector(ctx, secretNamespace, issuer.Spec())
+	if err != nil {
+		logger.Error(err, "failed to resolve container selector")
+		k8sutils.SetDigicertIssuerStatusConditionType(
+			ctx, r.Client, issuer, certmanagerv1beta1.ConditionConfigurationError, certmanagerv1beta1.ConditionTrue,
+			certmanagerv1beta1.ConditionReasonConfigMapNotFoundOrInvalid, err.Error(),
+		)
+		return ctrl.Result{}, err
+	}
+
+	prov, err := provisioners.New(fmt.Sprintf("%s/%s", req.Namespace, req.Name), issuerSpec, digicertAPIToken, r.ChainCache, logger, r.recorder)
 	if err != nil {
 		logger.Error(err, "failed to initialize provisioner")
 		return ctrl.Result{}, err
 	}
 
+	if validate := issuerSpec.Provisioner.ValidateCustomFields; validate != nil && *validate {
+		if err := prov.ValidateCustomFields(ctx); err != nil {
+			logger.Error(err, "custom order fields are invalid")
+			k8sutils.SetDigicertIssuerStatusConditionType(
+				ctx, r.Client, issuer, certmanagerv1beta1.ConditionConfigurationError, certmanagerv1beta1.ConditionTrue,
+				certmanagerv1beta1.ConditionReasonInvalidCustomFields, err.Error(),
+			)
+			return ctrl.Result{}, err
+		}
+	}
+
 	provisioners.Store(req.NamespacedName, prov)
 	logger.Info("provisioner is ready", "name", prov.GetName())
 
+	// Report whether new orders are held. Certificates of pending orders are still downloaded.
+	now := time.Now()
+	suspendedStatus, suspendedReason, suspendedMessage := certmanagerv1beta1.ConditionFalse, certmanagerv1beta1.ConditionReason(""), ""
+	if suspension := prov.Suspension(now); suspension != nil {
+		suspendedStatus, suspendedReason, suspendedMessage = certmanagerv1beta1.ConditionTrue, suspension.Reason, suspension.Message
+	}
+	issuer, _ = k8sutils.SetDigicertIssuerStatusConditionType(
+		ctx, r.Client, issuer, certmanagerv1beta1.ConditionSuspended, suspendedStatus, suspendedReason, suspendedMessage,
+	)
+
+	// Update the Suspended condition when the next issuance window starts or ends.
+	var result ctrl.Result
+	if next := prov.NextIssuanceWindowChange(now); !next.IsZero() {
+		result.RequeueAfter = next.Sub(now)
+	}
+
 	_, err = k8sutils.SetDigicertIssuerStatusConditionType(
 		ctx, r.Client, issuer, certmanagerv1beta1.ConditionReady, certmanagerv1beta1.ConditionTrue, "", "",
 	)
-	return ctrl.Result{}, err
+	return result, err
 }
 
 func (r *DigicertIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recorder = mgr.GetEventRecorderFor("digicertIssuer")
 	r.log = mgr.GetLogger().WithName("controllers").WithName("DigicertIssuer")
 	r.Client = mgr.GetClient()
+	r.apiReader = mgr.GetAPIReader()
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&certmanagerv1beta1.DigicertIssuer{}).
+		For(&certmanagerv1beta1.DigicertIssuer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&core.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.issuersForConfigMap)).
 		Complete(r)
 }
 
@@ -130,11 +198,53 @@ func (r *DigicertIssuerReconciler) SetupWithManagerClusterIssuer(mgr ctrl.Manage
 	r.recorder = mgr.GetEventRecorderFor("clusterDigicertIssuer")
 	r.log = mgr.GetLogger().WithName("controllers").WithName("ClusterDigicertIssuer")
 	r.Client = mgr.GetClient()
+	r.apiReader = mgr.GetAPIReader()
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&certmanagerv1beta1.ClusterDigicertIssuer{}).
+		For(&certmanagerv1beta1.ClusterDigicertIssuer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&core.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.issuersForConfigMap)).
 		Complete(r)
 }
 
+// issuersForConfigMap enqueues the issuers whose container selector references the config map.
+// Only config maps labelled with LabelKeyConfigMap are watched.
+// ClusterDigicertIssuers reference config maps in the cluster issuer namespace.
+func (r *DigicertIssuerReconciler) issuersForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	var specs map[types.NamespacedName]certmanagerv1beta1.DigicertIssuerSpec
+	if r.clusterIssuerNamespace == "" {
+		issuerList := new(certmanagerv1beta1.DigicertIssuerList)
+		if err := r.Client.List(ctx, issuerList, client.InNamespace(obj.GetNamespace())); err != nil {
+			r.log.Error(err, "failed to list issuers", "configMap", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+		specs = make(map[types.NamespacedName]certmanagerv1beta1.DigicertIssuerSpec, len(issuerList.Items))
+		for _, iss := range issuerList.Items {
+			specs[client.ObjectKeyFromObject(&iss)] = iss.Spec
+		}
+	} else {
+		if obj.GetNamespace() != r.clusterIssuerNamespace {
+			return nil
+		}
+		issuerList := new(certmanagerv1beta1.ClusterDigicertIssuerList)
+		if err := r.Client.List(ctx, issuerList); err != nil {
+			r.log.Error(err, "failed to list cluster issuers", "configMap", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+		specs = make(map[types.NamespacedName]certmanagerv1beta1.DigicertIssuerSpec, len(issuerList.Items))
+		for _, iss := range issuerList.Items {
+			specs[client.ObjectKeyFromObject(&iss)] = iss.Spec
+		}
+	}
+
+	var requests []reconcile.Request
+	for key, spec := range specs {
+		sel := spec.Provisioner.ContainerSelector
+		if sel != nil && sel.ConfigMapReference != nil && sel.ConfigMapReference.Name == obj.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+		}
+	}
+	return requests
+}
+
 func validateDigicertIssuerSpec(issuerSpec certmanagerv1beta1.DigicertIssuerSpec) error {
 	var errs error
 
@@ -151,6 +261,77 @@ func validateDigicertIssuerSpec(issuerSpec certmanagerv1beta1.DigicertIssuerSpec
 	if provisionerSpec.OrganizationID == nil && provisionerSpec.OrganizationName == "" {
 		errs = multierror.Append(errs, errors.New("spec.provisioner.organizationID or spec.provisioner.organizationName missing"))
 	}
+	if sel := provisionerSpec.ContainerSelector; sel != nil {
+		if sel.NamespaceLabel == "" {
+			errs = multierror.Append(errs, errors.New("spec.provisioner.containerSelector.namespaceLabel missing"))
+		}
+		if sel.ConfigMapReference != nil && sel.ConfigMapReference.Name == "" {
+			errs = multierror.Append(errs, errors.New("spec.provisioner.containerSelector.configMapReference.name missing"))
+		}
+	}
+	for idx, field := range provisionerSpec.CustomFields {
+		if field.MetadataID <= 0 {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.customFields[%d].metadataID missing", idx))
+		}
+	}
+	for idx, email := range provisionerSpec.AdditionalEmails {
+		if _, err := mail.ParseAddress(email); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.additionalEmails[%d] is invalid: %w", idx, err))
+		}
+	}
+	if err := provisioners.ValidateOrderTemplates(provisionerSpec); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := provisioners.ValidateCertificateSettings(provisionerSpec); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := provisioners.ValidateRequesterPolicy(issuerSpec.RequesterPolicy); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := provisioners.ValidateQuota(issuerSpec.Quota); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := provisioners.ValidateIssuanceWindows(issuerSpec.IssuanceWindows); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := provisioners.ValidatePollingPolicy(issuerSpec.Polling); err != nil {
+		errs = multierror.Append(errs, err)
+	}
 
 	return errs
 }
+
+// resolveContainerSelector merges the container mapping from the referenced config map into a copy of the issuer spec.
+// Entries given directly in the issuer spec take precedence over the config map.
+func (r *DigicertIssuerReconciler) resolveContainerSelector(ctx context.Context, namespace string, issuerSpec certmanagerv1beta1.DigicertIssuerSpec) (certmanagerv1beta1.DigicertIssuerSpec, error) {
+	sel := issuerSpec.Provisioner.ContainerSelector
+	if sel == nil || sel.ConfigMapReference == nil {
+		return issuerSpec, nil
+	}
+
+	// The config map is read from the API server, as it is not cached unless labelled.
+	reader := r.apiReader
+	if reader == nil {
+		reader = r.Client
+	}
+	data, err := k8sutils.GetConfigMapData(ctx, reader, namespace, sel.ConfigMapReference.Name)
+	if err != nil {
+		return issuerSpec, err
+	}
+
+	spec := *issuerSpec.DeepCopy()
+	containers := make(map[string]int, len(data))
+	for value, rawID := range data {
+		id, err := strconv.Atoi(strings.TrimSpace(rawID))
+		if err != nil {
+			return issuerSpec, fmt.Errorf("configmap %s/%s contains invalid container ID for %q: %w", namespace, sel.ConfigMapReference.Name, value, err)
+		}
+		containers[value] = id
+	}
+	for value, id := range spec.Provisioner.ContainerSelector.Containers {
+		containers[value] = id
+	}
+	spec.Provisioner.ContainerSelector.Containers = containers
+
+	return spec, nil
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func containerSelectorSpec(configMapName string) certmanagerv1beta1.DigicertIssuerSpec {
+	return certmanagerv1beta1.DigicertIssuerSpec{
+		Provisioner: certmanagerv1beta1.DigicertProvisioner{
+			ContainerSelector: &certmanagerv1beta1.ContainerSelector{
+				NamespaceLabel:     "division",
+				ConfigMapReference: &certmanagerv1beta1.ConfigMapReference{Name: configMapName},
+			},
+		},
+	}
+}
+
+func TestIssuersForConfigMap(t *testing.T) {
+	objs := []client.Object{
+		&certmanagerv1beta1.DigicertIssuer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "referencing"},
+			Spec:       containerSelectorSpec("divisions"),
+		},
+		&certmanagerv1beta1.DigicertIssuer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "other-config-map"},
+			Spec:       containerSelectorSpec("other"),
+		},
+		&certmanagerv1beta1.DigicertIssuer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "without-selector"},
+		},
+		&certmanagerv1beta1.DigicertIssuer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other-team", Name: "other-namespace"},
+			Spec:       containerSelectorSpec("divisions"),
+		},
+		&certmanagerv1beta1.ClusterDigicertIssuer{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-referencing"},
+			Spec:       containerSelectorSpec("divisions"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build()
+
+	tests := []struct {
+		name                   string
+		clusterIssuerNamespace string
+		configMap              types.NamespacedName
+		expected               []types.NamespacedName
+	}{
+		{
+			name:      "issuer",
+			configMap: types.NamespacedName{Namespace: "team", Name: "divisions"},
+			expected:  []types.NamespacedName{{Namespace: "team", Name: "referencing"}},
+		},
+		{
+			name:      "unreferenced",
+			configMap: types.NamespacedName{Namespace: "team", Name: "unreferenced"},
+		},
+		{
+			name:                   "cluster issuer",
+			clusterIssuerNamespace: "team",
+			configMap:              types.NamespacedName{Namespace: "team", Name: "divisions"},
+			expected:               []types.NamespacedName{{Name: "cluster-referencing"}},
+		},
+		{
+			name:                   "cluster issuer outside cluster issuer namespace",
+			clusterIssuerNamespace: "team",
+			configMap:              types.NamespacedName{Namespace: "other-team", Name: "divisions"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewDigicertIssuerReconciler(tt.clusterIssuerNamespace)
+			r.Client = c
+			r.log = logr.Discard()
+
+			cm := &core.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: tt.configMap.Namespace, Name: tt.configMap.Name}}
+			var got []types.NamespacedName
+			for _, req := range r.issuersForConfigMap(context.Background(), cm) {
+				got = append(got, req.NamespacedName)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].String() < got[j].String() })
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("unexpected requests, got=%v expected=%v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveContainerSelectorReadsUncachedConfigMap(t *testing.T) {
+	// The config map is not labelled, so it is missing from the cache but still read from the API server.
+	cm := &core.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "divisions"},
+		Data:       map[string]string{"cc-1234": "234567"},
+	}
+	r := NewDigicertIssuerReconciler("")
+	r.Client = fake.NewClientBuilder().WithScheme(newTestScheme(t)).Build()
+	r.apiReader = fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(cm).Build()
+	r.log = logr.Discard()
+
+	spec, err := r.resolveContainerSelector(context.Background(), "team", containerSelectorSpec("divisions"))
+	if err != nil {
+		t.Fatalf("resolveContainerSelector returned error: %v", err)
+	}
+	if got := spec.Provisioner.ContainerSelector.Containers["cc-1234"]; got != 234567 {
+		t.Fatalf("expected container of the config map, got %d", got)
+	}
+}
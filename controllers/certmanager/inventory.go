@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	inventoryListTimeout = 10 * time.Second
+
+	defaultMetricsRefreshInterval = time.Minute
+)
+
+var (
+	inventoryCertificateLabels = []string{"namespace", "certificate", "issuer", "order_id"}
+
+	descCertificateExpiry = prometheus.NewDesc(
+		"digicertissuer_certificate_expiry_timestamp_seconds",
+		"Unix timestamp the certificate issued by digicert-issuer expires at",
+		inventoryCertificateLabels, nil,
+	)
+
+	descCertificateRemainingDays = prometheus.NewDesc(
+		"digicertissuer_certificate_remaining_days",
+		"Number of days until the certificate issued by digicert-issuer expires",
+		inventoryCertificateLabels, nil,
+	)
+
+	descCertificates = prometheus.NewDesc(
+		"digicertissuer_certificates",
+		"Number of certificates issued by digicert-issuer including those exceeding the inventory limit",
+		[]string{"namespace", "issuer"}, nil,
+	)
+
+	descInventoryTruncated = prometheus.NewDesc(
+		"digicertissuer_certificate_inventory_truncated",
+		"1 if certificates were omitted from the inventory metrics because the limit was exceeded",
+		nil, nil,
+	)
+)
+
+// InventoryCollector exports metrics for every certificate issued by a DigicertIssuer or ClusterDigicertIssuer.
+// The metrics are computed from the cert-manager Certificates in an interval, so scrapes don't list them.
+type InventoryCollector struct {
+	client client.Reader
+	log    logr.Logger
+	now    func() time.Time
+
+	mu sync.Mutex
+	// inventory is the result of the last refresh. Nil until the first refresh succeeded.
+	inventory *inventory
+
+	// Limit is the maximum number of certificates per-certificate metrics are exported for.
+	// Certificates expiring first are preferred.
+	Limit int
+	// Interval the inventory is refreshed in.
+	Interval time.Duration
+}
+
+var (
+	_ prometheus.Collector = &InventoryCollector{}
+	_ manager.Runnable     = &InventoryCollector{}
+)
+
+func NewInventoryCollector(mgr ctrl.Manager, limit int) *InventoryCollector {
+	return &InventoryCollector{
+		client:   mgr.GetClient(),
+		log:      mgr.GetLogger().WithName("controllers").WithName("Inventory"),
+		now:      time.Now,
+		Limit:    limit,
+		Interval: defaultMetricsRefreshInterval,
+	}
+}
+
+// inventory are the certificates exported by the InventoryCollector.
+type inventory struct {
+	// counts is the number of certificates by namespace and issuer.
+	counts map[[2]string]int
+	// certs are the certificates expiring first, limited to InventoryCollector.Limit.
+	certs     []inventoryCertificate
+	truncated bool
+}
+
+// inventoryCertificate is a certificate issued by a DigicertIssuer.
+type inventoryCertificate struct {
+	namespace string
+	name      string
+	issuer    string
+	orderID   string
+	notAfter  time.Time
+}
+
+// Describe implements prometheus.Collector.
+func (c *InventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCertificateExpiry
+	ch <- descCertificateRemainingDays
+	ch <- descCertificates
+	ch <- descInventoryTruncated
+}
+
+// Collect implements prometheus.Collector.
+func (c *InventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	inv := c.inventory
+	c.mu.Unlock()
+	if inv == nil {
+		return
+	}
+
+	for key, count := range inv.counts {
+		ch <- prometheus.MustNewConstMetric(descCertificates, prometheus.GaugeValue, float64(count), key[0], key[1])
+	}
+
+	truncated := 0.0
+	if inv.truncated {
+		truncated = 1
+	}
+	ch <- prometheus.MustNewConstMetric(descInventoryTruncated, prometheus.GaugeValue, truncated)
+
+	now := c.now()
+	for _, cert := range inv.certs {
+		labels := []string{cert.namespace, cert.name, cert.issuer, cert.orderID}
+		ch <- prometheus.MustNewConstMetric(descCertificateExpiry, prometheus.GaugeValue, float64(cert.notAfter.Unix()), labels...)
+		ch <- prometheus.MustNewConstMetric(descCertificateRemainingDays, prometheus.GaugeValue, cert.notAfter.Sub(now).Hours()/24, labels...)
+	}
+}
+
+// Start implements manager.Runnable.
+func (c *InventoryCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Every replica exports the inventory.
+func (c *InventoryCollector) NeedLeaderElection() bool {
+	return false
+}
+
+// refresh recomputes the inventory. The previous inventory is kept if the certificates can't be listed.
+func (c *InventoryCollector) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, inventoryListTimeout)
+	defer cancel()
+
+	certs, err := c.listCertificates(ctx)
+	if err != nil {
+		c.log.Error(err, "failed to list certificates for inventory metrics")
+		return
+	}
+
+	inv := &inventory{counts: make(map[[2]string]int)}
+	for _, cert := range certs {
+		inv.counts[[2]string{cert.namespace, cert.issuer}]++
+	}
+
+	sort.Slice(certs, func(i, j int) bool {
+		if !certs[i].notAfter.Equal(certs[j].notAfter) {
+			return certs[i].notAfter.Before(certs[j].notAfter)
+		}
+		return certs[i].namespace+"/"+certs[i].name < certs[j].namespace+"/"+certs[j].name
+	})
+	if len(certs) > c.Limit {
+		certs, inv.truncated = certs[:c.Limit], true
+	}
+	inv.certs = certs
+
+	c.mu.Lock()
+	c.inventory = inv
+	c.mu.Unlock()
+}
+
+// listCertificates returns the issued Certificates referencing a DigicertIssuer or ClusterDigicertIssuer.
+// The order ID is taken from the CertificateRequest of the current revision.
+func (c *InventoryCollector) listCertificates(ctx context.Context) ([]inventoryCertificate, error) {
+	crtList := new(cmapi.CertificateList)
+	if err := c.client.List(ctx, crtList); err != nil {
+		return nil, err
+	}
+	crList := new(cmapi.CertificateRequestList)
+	if err := c.client.List(ctx, crList); err != nil {
+		return nil, err
+	}
+
+	orderIDs := make(map[string]string)
+	for idx := range crList.Items {
+		cr := &crList.Items[idx]
+		annotations := cr.GetAnnotations()
+		if orderID := annotations[annotationKeyOrderID]; orderID != "" {
+			orderIDs[cr.Namespace+"/"+annotations[cmapi.CertificateNameKey]+"/"+annotations[cmapi.CertificateRequestRevisionAnnotationKey]] = orderID
+		}
+	}
+
+	certs := make([]inventoryCertificate, 0)
+	for idx := range crtList.Items {
+		crt := &crtList.Items[idx]
+		if !isIssuedByDigicertIssuer(crt) || crt.Status.NotAfter == nil {
+			continue
+		}
+
+		var revision string
+		if crt.Status.Revision != nil {
+			revision = strconv.Itoa(*crt.Status.Revision)
+		}
+
+		certs = append(certs, inventoryCertificate{
+			namespace: crt.Namespace,
+			name:      crt.Name,
+			issuer:    issuerLabel(crt.Namespace, crt.Spec.IssuerRef),
+			orderID:   orderIDs[crt.Namespace+"/"+crt.Name+"/"+revision],
+			notAfter:  crt.Status.NotAfter.Time,
+		})
+	}
+	return certs, nil
+}
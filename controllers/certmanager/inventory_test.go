@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestInventoryCollector(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	revision := 2
+
+	newCertificate := func(name, issuerGroup string, notAfter time.Time) *cmapi.Certificate {
+		return &cmapi.Certificate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: name},
+			Spec: cmapi.CertificateSpec{
+				SecretName: name + "-tls",
+				IssuerRef:  cmmeta.ObjectReference{Group: issuerGroup, Kind: certmanagerv1beta1.DigicertIssuerKind, Name: "digicert"},
+			},
+			Status: cmapi.CertificateStatus{
+				NotAfter: &metav1.Time{Time: notAfter},
+				Revision: &revision,
+			},
+		}
+	}
+	objs := []client.Object{
+		newCertificate("expiring-first", certmanagerv1beta1.GroupVersion.Group, now.Add(24*time.Hour)),
+		newCertificate("expiring-last", certmanagerv1beta1.GroupVersion.Group, now.Add(48*time.Hour)),
+		newCertificate("other-issuer", "cert-manager.io", now.Add(time.Hour)),
+		&cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "expiring-first-2", Annotations: map[string]string{
+				annotationKeyOrderID:                          "123",
+				cmapi.CertificateNameKey:                      "expiring-first",
+				cmapi.CertificateRequestRevisionAnnotationKey: "2",
+			}},
+		},
+	}
+
+	c := &InventoryCollector{
+		client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build(),
+		log:    logr.Discard(),
+		now:    func() time.Time { return now },
+		Limit:  1,
+	}
+
+	// Nothing is exported before the first refresh.
+	if count := testutil.CollectAndCount(c); count != 0 {
+		t.Fatalf("expected no metrics before the first refresh, got %d", count)
+	}
+
+	c.refresh(context.Background())
+	expected := `
+# HELP digicertissuer_certificate_expiry_timestamp_seconds Unix timestamp the certificate issued by digicert-issuer expires at
+# TYPE digicertissuer_certificate_expiry_timestamp_seconds gauge
+digicertissuer_certificate_expiry_timestamp_seconds{certificate="expiring-first",issuer="team/digicert",namespace="team",order_id="123"} 1.7488656e+09
+# HELP digicertissuer_certificate_inventory_truncated 1 if certificates were omitted from the inventory metrics because the limit was exceeded
+# TYPE digicertissuer_certificate_inventory_truncated gauge
+digicertissuer_certificate_inventory_truncated 1
+# HELP digicertissuer_certificate_remaining_days Number of days until the certificate issued by digicert-issuer expires
+# TYPE digicertissuer_certificate_remaining_days gauge
+digicertissuer_certificate_remaining_days{certificate="expiring-first",issuer="team/digicert",namespace="team",order_id="123"} 1
+# HELP digicertissuer_certificates Number of certificates issued by digicert-issuer including those exceeding the inventory limit
+# TYPE digicertissuer_certificates gauge
+digicertissuer_certificates{issuer="team/digicert",namespace="team"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+
+	// The remaining days are computed on collection, the certificates only on refresh.
+	now = now.Add(12 * time.Hour)
+	if err := c.client.(client.Client).Delete(context.Background(), objs[1]); err != nil {
+		t.Fatalf("failed to delete certificate: %v", err)
+	}
+	expected = `
+# HELP digicertissuer_certificate_remaining_days Number of days until the certificate issued by digicert-issuer expires
+# TYPE digicertissuer_certificate_remaining_days gauge
+digicertissuer_certificate_remaining_days{certificate="expiring-first",issuer="team/digicert",namespace="team",order_id="123"} 0.5
+# HELP digicertissuer_certificates Number of certificates issued by digicert-issuer including those exceeding the inventory limit
+# TYPE digicertissuer_certificates gauge
+digicertissuer_certificates{issuer="team/digicert",namespace="team"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected), "digicertissuer_certificate_remaining_days", "digicertissuer_certificates"); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strings"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/k8sutils"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// issuerRefIndexKey indexes CertificateRequests by the kind and name of the DigicertIssuer or ClusterDigicertIssuer.
+// The namespace is not part of the key, as DigicertIssuers are also looked up in the default provider namespace.
+const issuerRefIndexKey = "spec.issuerRef.digicertIssuer"
+
+// issuerRefIndexValue returns the value CertificateRequests referencing the issuer are indexed by.
+func issuerRefIndexValue(kind, name string) string {
+	if strings.EqualFold(kind, certmanagerv1beta1.ClusterDigicertIssuerKind) {
+		return certmanagerv1beta1.ClusterDigicertIssuerKind + "/" + name
+	}
+	return certmanagerv1beta1.DigicertIssuerKind + "/" + name
+}
+
+func indexCertificateRequestByIssuerRef(obj client.Object) []string {
+	ref := obj.(*cmapi.CertificateRequest).Spec.IssuerRef
+	if ref.Group != certmanagerv1beta1.GroupVersion.Group {
+		return nil
+	}
+	return []string{issuerRefIndexValue(ref.Kind, ref.Name)}
+}
+
+// asIssuer wraps the watched DigicertIssuer or ClusterDigicertIssuer.
+func asIssuer(obj client.Object) k8sutils.Issuer {
+	switch iss := obj.(type) {
+	case *certmanagerv1beta1.DigicertIssuer:
+		return &k8sutils.DigicertIssuer{DigicertIssuer: *iss}
+	case *certmanagerv1beta1.ClusterDigicertIssuer:
+		return &k8sutils.ClusterDigicertIssuer{ClusterDigicertIssuer: *iss}
+	}
+	return nil
+}
+
+func isDigicertIssuerSuspended(issuer k8sutils.Issuer) bool {
+	status := issuer.Status()
+	if status == nil {
+		return false
+	}
+
+	for _, condition := range status.Conditions {
+		if condition.Type == certmanagerv1beta1.ConditionSuspended && condition.Status == certmanagerv1beta1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// issuerBecameReady passes the events of issuers starting to place orders,
+// i.e. becoming Ready or being resumed while Ready.
+var issuerBecameReady = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		return isDigicertIssuerReady(asIssuer(e.Object))
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldIss, newIss := asIssuer(e.ObjectOld), asIssuer(e.ObjectNew)
+		if !isDigicertIssuerReady(newIss) {
+			return false
+		}
+		return !isDigicertIssuerReady(oldIss) || (isDigicertIssuerSuspended(oldIss) && !isDigicertIssuerSuspended(newIss))
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		return false
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		return false
+	},
+}
+
+// requestsForIssuer returns the waiting CertificateRequests referencing the issuer.
+func (r *CertificateRequestReconciler) requestsForIssuer(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := certmanagerv1beta1.DigicertIssuerKind
+	if _, ok := obj.(*certmanagerv1beta1.ClusterDigicertIssuer); ok {
+		kind = certmanagerv1beta1.ClusterDigicertIssuerKind
+	}
+
+	crList := new(cmapi.CertificateRequestList)
+	if err := r.Client.List(ctx, crList, client.MatchingFields{issuerRefIndexKey: issuerRefIndexValue(kind, obj.GetName())}); err != nil {
+		r.log.Error(err, "failed to list certificate requests of issuer", "kind", kind, "name", obj.GetName())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0)
+	for idx := range crList.Items {
+		cr := &crList.Items[idx]
+		// DigicertIssuers serve requests of their namespace, and of all namespaces if in the default provider namespace.
+		if kind == certmanagerv1beta1.DigicertIssuerKind && cr.Namespace != obj.GetNamespace() && obj.GetNamespace() != r.DefaultProviderNamespace {
+			continue
+		}
+		if len(cr.Status.Certificate) > 0 || isCertificateRequestFailed(cr) || isCertificateRequestIssued(cr) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cr.Namespace, Name: cr.Name}})
+	}
+	return requests
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newIssuerWithConditions(ready, suspended bool) *certmanagerv1beta1.DigicertIssuer {
+	toStatus := func(b bool) certmanagerv1beta1.ConditionStatus {
+		if b {
+			return certmanagerv1beta1.ConditionTrue
+		}
+		return certmanagerv1beta1.ConditionFalse
+	}
+	return &certmanagerv1beta1.DigicertIssuer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "digicert"},
+		Status: &certmanagerv1beta1.DigicertIssuerStatus{
+			Conditions: []certmanagerv1beta1.DigicertIssuerCondition{
+				{Type: certmanagerv1beta1.ConditionReady, Status: toStatus(ready)},
+				{Type: certmanagerv1beta1.ConditionSuspended, Status: toStatus(suspended)},
+			},
+		},
+	}
+}
+
+func TestIssuerBecameReady(t *testing.T) {
+	tests := []struct {
+		name     string
+		old      *certmanagerv1beta1.DigicertIssuer
+		new      *certmanagerv1beta1.DigicertIssuer
+		expected bool
+	}{
+		{
+			name:     "not ready to ready",
+			old:      newIssuerWithConditions(false, false),
+			new:      newIssuerWithConditions(true, false),
+			expected: true,
+		},
+		{
+			name: "ready to ready",
+			old:  newIssuerWithConditions(true, false),
+			new:  newIssuerWithConditions(true, false),
+		},
+		{
+			name: "ready to not ready",
+			old:  newIssuerWithConditions(true, false),
+			new:  newIssuerWithConditions(false, false),
+		},
+		{
+			name:     "resumed while ready",
+			old:      newIssuerWithConditions(true, true),
+			new:      newIssuerWithConditions(true, false),
+			expected: true,
+		},
+		{
+			name: "suspended while ready",
+			old:  newIssuerWithConditions(true, false),
+			new:  newIssuerWithConditions(true, true),
+		},
+		{
+			name: "without status",
+			old:  &certmanagerv1beta1.DigicertIssuer{},
+			new:  &certmanagerv1beta1.DigicertIssuer{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := issuerBecameReady.Update(event.UpdateEvent{ObjectOld: tt.old, ObjectNew: tt.new}); got != tt.expected {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	if !issuerBecameReady.Create(event.CreateEvent{Object: newIssuerWithConditions(true, false)}) {
+		t.Fatalf("expected the creation of a ready issuer to pass")
+	}
+	if issuerBecameReady.Create(event.CreateEvent{Object: newIssuerWithConditions(false, false)}) {
+		t.Fatalf("expected the creation of a not ready issuer not to pass")
+	}
+	if issuerBecameReady.Delete(event.DeleteEvent{Object: newIssuerWithConditions(true, false)}) {
+		t.Fatalf("expected deletions not to pass")
+	}
+}
+
+func TestRequestsForIssuer(t *testing.T) {
+	newRequest := func(namespace, name, kind, issuer string, conditions ...cmapi.CertificateRequestCondition) *cmapi.CertificateRequest {
+		return &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: cmapi.CertificateRequestSpec{
+				IssuerRef: cmmeta.ObjectReference{Group: certmanagerv1beta1.GroupVersion.Group, Kind: kind, Name: issuer},
+			},
+			Status: cmapi.CertificateRequestStatus{Conditions: conditions},
+		}
+	}
+	readyCondition := func(reason string) cmapi.CertificateRequestCondition {
+		return cmapi.CertificateRequestCondition{Type: cmapi.CertificateRequestConditionReady, Status: cmmeta.ConditionFalse, Reason: reason}
+	}
+
+	issuedWithCertificate := newRequest("team", "issued-with-certificate", certmanagerv1beta1.DigicertIssuerKind, "digicert")
+	issuedWithCertificate.Status.Certificate = []byte("certificate")
+	objs := []client.Object{
+		newRequest("team", "waiting", certmanagerv1beta1.DigicertIssuerKind, "digicert", readyCondition(cmapi.CertificateRequestReasonPending)),
+		newRequest("team", "new", certmanagerv1beta1.DigicertIssuerKind, "digicert"),
+		newRequest("team", "failed", certmanagerv1beta1.DigicertIssuerKind, "digicert", readyCondition(cmapi.CertificateRequestReasonFailed)),
+		newRequest("team", "denied", certmanagerv1beta1.DigicertIssuerKind, "digicert", readyCondition(cmapi.CertificateRequestReasonDenied)),
+		newRequest("team", "issued", certmanagerv1beta1.DigicertIssuerKind, "digicert", readyCondition(cmapi.CertificateRequestReasonIssued)),
+		issuedWithCertificate,
+		newRequest("team", "other-issuer", certmanagerv1beta1.DigicertIssuerKind, "other"),
+		newRequest("other-team", "other-namespace", certmanagerv1beta1.DigicertIssuerKind, "digicert"),
+		newRequest("other-team", "cluster-issuer", certmanagerv1beta1.ClusterDigicertIssuerKind, "digicert"),
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).
+		WithIndex(&cmapi.CertificateRequest{}, issuerRefIndexKey, indexCertificateRequestByIssuerRef).Build()
+
+	tests := []struct {
+		name     string
+		issuer   client.Object
+		expected []types.NamespacedName
+	}{
+		{
+			name:   "issuer",
+			issuer: &certmanagerv1beta1.DigicertIssuer{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "digicert"}},
+			expected: []types.NamespacedName{
+				{Namespace: "team", Name: "new"},
+				{Namespace: "team", Name: "waiting"},
+			},
+		},
+		{
+			name:   "issuer in the default provider namespace",
+			issuer: &certmanagerv1beta1.DigicertIssuer{ObjectMeta: metav1.ObjectMeta{Namespace: "provider", Name: "digicert"}},
+			expected: []types.NamespacedName{
+				{Namespace: "other-team", Name: "other-namespace"},
+				{Namespace: "team", Name: "new"},
+				{Namespace: "team", Name: "waiting"},
+			},
+		},
+		{
+			name:     "cluster issuer",
+			issuer:   &certmanagerv1beta1.ClusterDigicertIssuer{ObjectMeta: metav1.ObjectMeta{Name: "digicert"}},
+			expected: []types.NamespacedName{{Namespace: "other-team", Name: "cluster-issuer"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &CertificateRequestReconciler{Client: c, log: logr.Discard(), DefaultProviderNamespace: "provider"}
+
+			var got []types.NamespacedName
+			for _, req := range r.requestsForIssuer(context.Background(), tt.issuer) {
+				got = append(got, req.NamespacedName)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i].String() < got[j].String() })
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Fatalf("unexpected requests, got=%v expected=%v", got, tt.expected)
+			}
+		})
+	}
+}
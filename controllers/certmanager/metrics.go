@@ -27,10 +27,15 @@ import (
 func init() {
 	metrics.Registry.MustRegister(
 		metricRequestsPending, metricRequestErrors, metricIssuerNotReady,
+		metricOrders, metricIntermediateRotations, metricIntermediateRotationTimestamp,
+		metricCertificateRevoked, metricRevocationCheckErrors, metricOrderIssueDuration, metricQuotaExceeded,
+		metricOrderStatusChanges,
 	)
 }
 
 var (
+	// metricRequestsPending and metricRequestErrors create series per CertificateRequest.
+	// They are only updated with --legacy-request-metrics and superseded by digicertissuer_certificate_requests.
 	metricRequestsPending = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "digicertissuer_request_pending_total",
@@ -67,4 +72,92 @@ var (
 			"reason",
 		},
 	)
+
+	metricOrders = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_orders_total",
+			Help: "Number of orders placed with CertCentral",
+		},
+		[]string{
+			"issuer",
+			"order_type",
+			"container_id",
+		},
+	)
+
+	metricIntermediateRotations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_intermediate_rotations_total",
+			Help: "Number of times an issued certificate was signed by a different intermediate than the previous one",
+		},
+		[]string{
+			"issuer",
+		},
+	)
+
+	metricIntermediateRotationTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "digicertissuer_intermediate_rotation_timestamp_seconds",
+			Help: "Unix timestamp of the last intermediate rotation",
+		},
+		[]string{
+			"issuer",
+		},
+	)
+
+	metricCertificateRevoked = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "digicertissuer_certificate_revoked",
+			Help: "Whether the certificate issued by digicert-issuer was revoked",
+		},
+		[]string{
+			"namespace",
+			"certificate",
+		},
+	)
+
+	metricRevocationCheckErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_revocation_check_errors_total",
+			Help: "Number of failed revocation status checks",
+		},
+		[]string{
+			"namespace",
+			"certificate",
+		},
+	)
+
+	metricQuotaExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_quota_exceeded_total",
+			Help: "Number of times a certificate request was held because the issuance quota was exceeded",
+		},
+		[]string{
+			"issuer",
+			"namespace",
+			"limit",
+		},
+	)
+
+	metricOrderStatusChanges = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_order_status_changes_total",
+			Help: "Number of order status changes of pending certificate requests found by the order status poller",
+		},
+		[]string{
+			"issuer",
+			"status",
+		},
+	)
+
+	metricOrderIssueDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "digicertissuer_order_issue_duration_seconds",
+			Help:    "Duration from placing the order until the certificate was issued",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		},
+		[]string{
+			"issuer",
+		},
+	)
 )
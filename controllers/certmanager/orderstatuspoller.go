@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// orderStatusPollOverlap is subtracted from the start of the polled period to tolerate clock skew.
+	orderStatusPollOverlap = time.Minute
+
+	defaultOrderStatusPollFallbackInterval = time.Hour
+
+	// orderStatusEventBuffer is the number of wake-ups buffered, so polling is not blocked by a busy controller.
+	orderStatusEventBuffer = 1000
+)
+
+// OrderStatusPoller lists the orders whose status changed in one call per CertCentral account and interval
+// and triggers a reconcile of the pending CertificateRequests of these orders.
+// Polling every pending CertificateRequest individually becomes a slow fallback.
+type OrderStatusPoller struct {
+	client client.Reader
+	log    logr.Logger
+	now    func() time.Time
+	events chan event.GenericEvent
+	load   func(types.NamespacedName) (orderStatusLister, bool)
+
+	// lastPoll is the end of the period last polled successfully by account. Only accessed by Start.
+	lastPoll map[string]time.Time
+
+	mu sync.Mutex
+	// changed are the CertificateRequests whose orders changed since they were last reconciled.
+	changed map[types.NamespacedName]bool
+
+	// Interval between polls of each account.
+	Interval time.Duration
+	// FallbackInterval is the minimum interval between polls of a single pending CertificateRequest.
+	FallbackInterval time.Duration
+	// DefaultProviderNamespace is the namespace DigicertIssuers are looked up in if not found in the request's namespace.
+	DefaultProviderNamespace string
+}
+
+func NewOrderStatusPoller(mgr ctrl.Manager, interval time.Duration) *OrderStatusPoller {
+	return &OrderStatusPoller{
+		client:           mgr.GetClient(),
+		log:              mgr.GetLogger().WithName("controllers").WithName("OrderStatusPoller"),
+		now:              time.Now,
+		events:           make(chan event.GenericEvent, orderStatusEventBuffer),
+		load:             loadOrderStatusLister,
+		lastPoll:         make(map[string]time.Time),
+		changed:          make(map[types.NamespacedName]bool),
+		Interval:         interval,
+		FallbackInterval: defaultOrderStatusPollFallbackInterval,
+	}
+}
+
+// orderStatusLister lists the order status changes of a CertCentral account.
+type orderStatusLister interface {
+	Account() string
+	ListOrderStatusChanges(ctx context.Context, from, to time.Time) ([]provisioners.OrderStatusChange, error)
+}
+
+// loadOrderStatusLister returns the provisioner of the issuer.
+func loadOrderStatusLister(issuer types.NamespacedName) (orderStatusLister, bool) {
+	provisioner, ok := provisioners.Load(issuer)
+	if !ok {
+		return nil, false
+	}
+	return provisioner, true
+}
+
+// Source returns the source of the CertificateRequests whose orders changed.
+func (p *OrderStatusPoller) Source() source.Source {
+	return source.Channel(p.events, &handler.EnqueueRequestForObject{})
+}
+
+// Start implements manager.Runnable.
+func (p *OrderStatusPoller) Start(ctx context.Context) error {
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// TakeChanged returns whether the order of the CertificateRequest changed since it was last called for the request.
+// It must be called on every reconcile of the request, so the changes of deleted or completed requests are forgotten.
+func (p *OrderStatusPoller) TakeChanged(key types.NamespacedName) bool {
+	if p == nil {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := p.changed[key]
+	delete(p.changed, key)
+	return changed
+}
+
+// fallbackInterval returns the minimum interval between polls of a single pending CertificateRequest.
+func (p *OrderStatusPoller) fallbackInterval() time.Duration {
+	if p == nil {
+		return 0
+	}
+	return p.FallbackInterval
+}
+
+// pendingAccount are the pending CertificateRequests of a CertCentral account by order ID.
+type pendingAccount struct {
+	provisioner orderStatusLister
+	requests    map[string][]*cmapi.CertificateRequest
+}
+
+func (p *OrderStatusPoller) poll(ctx context.Context) {
+	crList := new(cmapi.CertificateRequestList)
+	if err := p.client.List(ctx, crList); err != nil {
+		p.log.Error(err, "failed to list certificate requests")
+		return
+	}
+
+	accounts := make(map[string]*pendingAccount)
+	for idx := range crList.Items {
+		cr := &crList.Items[idx]
+		orderID := cr.GetAnnotations()[annotationKeyOrderID]
+		if cr.Spec.IssuerRef.Group != certmanagerv1beta1.GroupVersion.Group || orderID == "" ||
+			len(cr.Status.Certificate) > 0 || !isCertificateRequestPending(cr) {
+			continue
+		}
+
+		provisioner, ok := p.loadProvisioner(cr)
+		if !ok {
+			continue
+		}
+		account, ok := accounts[provisioner.Account()]
+		if !ok {
+			account = &pendingAccount{
+				provisioner: provisioner,
+				requests:    make(map[string][]*cmapi.CertificateRequest),
+			}
+			accounts[provisioner.Account()] = account
+		}
+		account.requests[orderID] = append(account.requests[orderID], cr)
+	}
+
+	// Forget accounts without pending requests. They are polled from the fallback interval ago again.
+	for id := range p.lastPoll {
+		if _, ok := accounts[id]; !ok {
+			delete(p.lastPoll, id)
+		}
+	}
+
+	now := p.now()
+	for id, account := range accounts {
+		// Changes before are caught by polling the pending requests individually.
+		from := now.Add(-p.FallbackInterval)
+		if last, ok := p.lastPoll[id]; ok && last.Add(-orderStatusPollOverlap).After(from) {
+			from = last.Add(-orderStatusPollOverlap)
+		}
+
+		changes, err := account.provisioner.ListOrderStatusChanges(ctx, from, now)
+		if err != nil {
+			p.log.Error(err, "failed to list order status changes", "account", id)
+			continue
+		}
+		p.lastPoll[id] = now
+
+		for _, change := range changes {
+			for _, cr := range account.requests[strconv.Itoa(change.OrderID)] {
+				p.log.V(4).Info("order status changed", "certificaterequest", client.ObjectKeyFromObject(cr), "orderID", change.OrderID, "status", change.Status)
+				p.mu.Lock()
+				p.changed[client.ObjectKeyFromObject(cr)] = true
+				p.mu.Unlock()
+				metricOrderStatusChanges.WithLabelValues(issuerLabel(cr.Namespace, cr.Spec.IssuerRef), change.Status).Inc()
+
+				select {
+				case p.events <- event.GenericEvent{Object: cr}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// loadProvisioner returns the provisioner of the request's issuer the same way the CertificateRequestReconciler does.
+func (p *OrderStatusPoller) loadProvisioner(cr *cmapi.CertificateRequest) (orderStatusLister, bool) {
+	if strings.EqualFold(cr.Spec.IssuerRef.Kind, certmanagerv1beta1.ClusterDigicertIssuerKind) {
+		return p.load(types.NamespacedName{Name: cr.Spec.IssuerRef.Name})
+	}
+	if provisioner, ok := p.load(types.NamespacedName{Namespace: cr.Namespace, Name: cr.Spec.IssuerRef.Name}); ok {
+		return provisioner, ok
+	}
+	return p.load(types.NamespacedName{Namespace: p.DefaultProviderNamespace, Name: cr.Spec.IssuerRef.Name})
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+type orderStatusListCall struct {
+	from, to time.Time
+}
+
+type mockOrderStatusLister struct {
+	account string
+	changes []provisioners.OrderStatusChange
+	calls   []orderStatusListCall
+}
+
+func (m *mockOrderStatusLister) Account() string {
+	return m.account
+}
+
+func (m *mockOrderStatusLister) ListOrderStatusChanges(_ context.Context, from, to time.Time) ([]provisioners.OrderStatusChange, error) {
+	m.calls = append(m.calls, orderStatusListCall{from: from, to: to})
+	return m.changes, nil
+}
+
+func newPendingCertificateRequest(name, issuer, orderID string, created time.Time) *cmapi.CertificateRequest {
+	return &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "team",
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Annotations: map[string]string{
+				annotationKeyOrderID:        orderID,
+				annotationKeyDigicertIssuer: "true",
+			},
+		},
+		Spec: cmapi.CertificateRequestSpec{
+			IssuerRef: cmmeta.ObjectReference{
+				Group: certmanagerv1beta1.GroupVersion.Group,
+				Kind:  certmanagerv1beta1.DigicertIssuerKind,
+				Name:  issuer,
+			},
+		},
+		Status: cmapi.CertificateRequestStatus{
+			Conditions: []cmapi.CertificateRequestCondition{{
+				Type:   cmapi.CertificateRequestConditionReady,
+				Status: cmmeta.ConditionFalse,
+				Reason: cmapi.CertificateRequestReasonPending,
+			}},
+		},
+	}
+}
+
+func TestOrderStatusPollerPoll(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := now.Add(-30 * 24 * time.Hour)
+
+	issued := newPendingCertificateRequest("issued", "first", "4", created)
+	issued.Status.Certificate = []byte("certificate")
+	objs := []client.Object{
+		newPendingCertificateRequest("changed", "first", "1", created),
+		newPendingCertificateRequest("unchanged", "second", "2", created),
+		newPendingCertificateRequest("other-account", "third", "3", created),
+		issued,
+	}
+
+	shared := &mockOrderStatusLister{
+		account: "shared",
+		changes: []provisioners.OrderStatusChange{{OrderID: 1, Status: "issued"}, {OrderID: 4, Status: "issued"}},
+	}
+	other := &mockOrderStatusLister{account: "other"}
+	listers := map[types.NamespacedName]orderStatusLister{
+		{Namespace: "team", Name: "first"}:  shared,
+		{Namespace: "team", Name: "second"}: shared,
+		{Namespace: "team", Name: "third"}:  other,
+	}
+
+	p := &OrderStatusPoller{
+		client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build(),
+		log:    logr.Discard(),
+		now:    func() time.Time { return now },
+		events: make(chan event.GenericEvent, 10),
+		load: func(issuer types.NamespacedName) (orderStatusLister, bool) {
+			lister, ok := listers[issuer]
+			return lister, ok
+		},
+		lastPoll:         make(map[string]time.Time),
+		changed:          make(map[types.NamespacedName]bool),
+		FallbackInterval: time.Hour,
+	}
+
+	p.poll(context.Background())
+
+	// The pending requests of issuers sharing an account are polled in one call.
+	// The first poll starts one fallback interval ago instead of at the oldest request.
+	expectedCalls := []orderStatusListCall{{from: now.Add(-time.Hour), to: now}}
+	if !reflect.DeepEqual(shared.calls, expectedCalls) {
+		t.Fatalf("unexpected calls of the shared account, got=%v expected=%v", shared.calls, expectedCalls)
+	}
+	if !reflect.DeepEqual(other.calls, expectedCalls) {
+		t.Fatalf("unexpected calls of the other account, got=%v expected=%v", other.calls, expectedCalls)
+	}
+
+	// Only the pending request whose order changed is woken up.
+	var woken []string
+	for len(p.events) > 0 {
+		woken = append(woken, (<-p.events).Object.GetName())
+	}
+	sort.Strings(woken)
+	if !reflect.DeepEqual(woken, []string{"changed"}) {
+		t.Fatalf("unexpected woken requests: %v", woken)
+	}
+	changed := client.ObjectKeyFromObject(objs[0])
+	if !p.TakeChanged(changed) || p.TakeChanged(changed) {
+		t.Fatalf("expected the change to be taken once")
+	}
+	if p.TakeChanged(client.ObjectKeyFromObject(objs[1])) {
+		t.Fatalf("expected no change of the unchanged request")
+	}
+
+	// Subsequent polls continue from the last poll.
+	last := now
+	now = now.Add(5 * time.Minute)
+	p.poll(context.Background())
+	expectedCalls = append(expectedCalls, orderStatusListCall{from: last.Add(-orderStatusPollOverlap), to: now})
+	if !reflect.DeepEqual(shared.calls, expectedCalls) {
+		t.Fatalf("unexpected calls of the shared account, got=%v expected=%v", shared.calls, expectedCalls)
+	}
+}
+
+func TestCertificateRequestReconcileForgetsOrderChanges(t *testing.T) {
+	deleted := types.NamespacedName{Namespace: "team", Name: "deleted"}
+	poller := &OrderStatusPoller{changed: map[types.NamespacedName]bool{deleted: true}}
+	r := &CertificateRequestReconciler{
+		Client:            fake.NewClientBuilder().WithScheme(newTestScheme(t)).Build(),
+		log:               logr.Discard(),
+		OrderStatusPoller: poller,
+	}
+
+	if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: deleted}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if len(poller.changed) != 0 {
+		t.Fatalf("expected the change of the deleted request to be forgotten, got %v", poller.changed)
+	}
+}
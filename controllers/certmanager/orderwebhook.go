@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strconv"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/sapcc/digicert-issuer/pkg/orderwebhook"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// orderIDIndexKey indexes CertificateRequests by the ID of their DigiCert order.
+const orderIDIndexKey = "metadata.annotations.digicert-order-id"
+
+// OrderCallbackReceiver wakes the pending CertificateRequests of orders reported by CertCentral callbacks.
+// The request is patched, so the reconcile is triggered regardless of the replica receiving the callback.
+type OrderCallbackReceiver struct {
+	client client.Client
+	log    logr.Logger
+}
+
+func NewOrderCallbackReceiver(mgr ctrl.Manager) *OrderCallbackReceiver {
+	return &OrderCallbackReceiver{
+		client: mgr.GetClient(),
+		log:    mgr.GetLogger().WithName("controllers").WithName("OrderCallbackReceiver"),
+	}
+}
+
+// SetupWithManager indexes the CertificateRequests by order ID.
+func (r *OrderCallbackReceiver) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	return mgr.GetFieldIndexer().IndexField(ctx, &cmapi.CertificateRequest{}, orderIDIndexKey, func(obj client.Object) []string {
+		if orderID := obj.GetAnnotations()[annotationKeyOrderID]; orderID != "" {
+			return []string{orderID}
+		}
+		return nil
+	})
+}
+
+// Notify records the order status on the pending CertificateRequests of the order and makes their poll due.
+func (r *OrderCallbackReceiver) Notify(ctx context.Context, event orderwebhook.Event) error {
+	crList := new(cmapi.CertificateRequestList)
+	if err := r.client.List(ctx, crList, client.MatchingFields{orderIDIndexKey: strconv.Itoa(event.Data.OrderID)}); err != nil {
+		return err
+	}
+
+	for idx := range crList.Items {
+		curCR := &crList.Items[idx]
+		if len(curCR.Status.Certificate) > 0 || !isCertificateRequestPending(curCR) {
+			continue
+		}
+
+		cr := curCR.DeepCopy()
+		annotations := cr.GetAnnotations()
+		annotations[annotationKeyOrderStatus] = event.Data.Status
+		delete(annotations, provisioners.AnnotationKeyNextPoll)
+		cr.SetAnnotations(annotations)
+		if err := r.client.Patch(ctx, cr, client.MergeFrom(curCR)); err != nil {
+			return err
+		}
+		r.log.Info("order status changed", "certificaterequest", client.ObjectKeyFromObject(cr), "orderID", event.Data.OrderID, "status", event.Data.Status)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	requestStatePending = "pending"
+	requestStateFailed  = "failed"
+)
+
+var descCertificateRequests = prometheus.NewDesc(
+	"digicertissuer_certificate_requests",
+	"Number of pending and failed certificate requests",
+	[]string{"namespace", "issuer", "state"}, nil,
+)
+
+// RequestsCollector exports the number of pending and failed CertificateRequests per issuer and namespace.
+// The metrics are computed from the CertificateRequests in an interval, so deleted requests are not counted and
+// scrapes don't list them.
+type RequestsCollector struct {
+	client client.Reader
+	log    logr.Logger
+
+	mu sync.Mutex
+	// counts are the requests by namespace, issuer and state of the last refresh. Nil until the first refresh succeeded.
+	counts map[[3]string]int
+
+	// Interval the counts are refreshed in.
+	Interval time.Duration
+}
+
+var (
+	_ prometheus.Collector = &RequestsCollector{}
+	_ manager.Runnable     = &RequestsCollector{}
+)
+
+func NewRequestsCollector(mgr ctrl.Manager) *RequestsCollector {
+	return &RequestsCollector{
+		client:   mgr.GetClient(),
+		log:      mgr.GetLogger().WithName("controllers").WithName("RequestsMetrics"),
+		Interval: defaultMetricsRefreshInterval,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *RequestsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCertificateRequests
+}
+
+// Collect implements prometheus.Collector.
+func (c *RequestsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	counts := c.counts
+	c.mu.Unlock()
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(descCertificateRequests, prometheus.GaugeValue, float64(count), key[0], key[1], key[2])
+	}
+}
+
+// Start implements manager.Runnable.
+func (c *RequestsCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		c.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+// Every replica exports the counts.
+func (c *RequestsCollector) NeedLeaderElection() bool {
+	return false
+}
+
+// refresh recounts the requests. The previous counts are kept if the requests can't be listed.
+func (c *RequestsCollector) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, inventoryListTimeout)
+	defer cancel()
+
+	crList := new(cmapi.CertificateRequestList)
+	if err := c.client.List(ctx, crList); err != nil {
+		c.log.Error(err, "failed to list certificate requests for metrics")
+		return
+	}
+
+	counts := make(map[[3]string]int)
+	for idx := range crList.Items {
+		cr := &crList.Items[idx]
+		if cr.Spec.IssuerRef.Group != certmanagerv1beta1.GroupVersion.Group {
+			continue
+		}
+
+		var state string
+		switch apiutil.CertificateRequestReadyReason(cr) {
+		case cmapi.CertificateRequestReasonFailed:
+			state = requestStateFailed
+		case cmapi.CertificateRequestReasonPending:
+			state = requestStatePending
+		default:
+			continue
+		}
+
+		counts[[3]string{cr.Namespace, issuerLabel(cr.Namespace, cr.Spec.IssuerRef), state}]++
+	}
+
+	c.mu.Lock()
+	c.counts = counts
+	c.mu.Unlock()
+}
+
+// issuerLabel returns the issuer referenced from the namespace in the format used by the issuer label of all metrics.
+func issuerLabel(namespace string, ref cmmeta.IssuerReference) string {
+	issuer := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+	if strings.EqualFold(ref.Kind, certmanagerv1beta1.ClusterDigicertIssuerKind) {
+		issuer.Namespace = ""
+	}
+	return issuer.String()
+}
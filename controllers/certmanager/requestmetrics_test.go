@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRequestsCollector(t *testing.T) {
+	newRequest := func(name, group, reason string) *cmapi.CertificateRequest {
+		return &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: name},
+			Spec: cmapi.CertificateRequestSpec{
+				IssuerRef: cmmeta.ObjectReference{Group: group, Kind: certmanagerv1beta1.DigicertIssuerKind, Name: "digicert"},
+			},
+			Status: cmapi.CertificateRequestStatus{
+				Conditions: []cmapi.CertificateRequestCondition{{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: reason,
+				}},
+			},
+		}
+	}
+	objs := []client.Object{
+		newRequest("pending", certmanagerv1beta1.GroupVersion.Group, cmapi.CertificateRequestReasonPending),
+		newRequest("other-pending", certmanagerv1beta1.GroupVersion.Group, cmapi.CertificateRequestReasonPending),
+		newRequest("failed", certmanagerv1beta1.GroupVersion.Group, cmapi.CertificateRequestReasonFailed),
+		newRequest("issued", certmanagerv1beta1.GroupVersion.Group, cmapi.CertificateRequestReasonIssued),
+		newRequest("other-issuer", "cert-manager.io", cmapi.CertificateRequestReasonPending),
+	}
+
+	c := &RequestsCollector{
+		client: fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(objs...).Build(),
+		log:    logr.Discard(),
+	}
+
+	// Nothing is exported before the first refresh.
+	if count := testutil.CollectAndCount(c); count != 0 {
+		t.Fatalf("expected no metrics before the first refresh, got %d", count)
+	}
+
+	c.refresh(context.Background())
+	expected := `
+# HELP digicertissuer_certificate_requests Number of pending and failed certificate requests
+# TYPE digicertissuer_certificate_requests gauge
+digicertissuer_certificate_requests{issuer="team/digicert",namespace="team",state="failed"} 1
+digicertissuer_certificate_requests{issuer="team/digicert",namespace="team",state="pending"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+
+	// Deleted requests are only forgotten on the next refresh.
+	if err := c.client.(client.Client).Delete(context.Background(), objs[2]); err != nil {
+		t.Fatalf("failed to delete certificate request: %v", err)
+	}
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected metrics before refresh: %v", err)
+	}
+	c.refresh(context.Background())
+	expected = `
+# HELP digicertissuer_certificate_requests Number of pending and failed certificate requests
+# TYPE digicertissuer_certificate_requests gauge
+digicertissuer_certificate_requests{issuer="team/digicert",namespace="team",state="pending"} 2
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected)); err != nil {
+		t.Fatalf("unexpected metrics after refresh: %v", err)
+	}
+}
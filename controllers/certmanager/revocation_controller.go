@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/audit"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// reasonRevoked is the reason of the Issuing condition set to reissue revoked certificates.
+const reasonRevoked = "Revoked"
+
+// RevocationReconciler periodically checks the revocation status of certificates issued by DigicertIssuers.
+type RevocationReconciler struct {
+	client.Client
+	log      logr.Logger
+	recorder record.EventRecorder
+	checker  *provisioners.RevocationChecker
+
+	// CheckInterval is the interval the revocation status of each certificate is checked in.
+	CheckInterval time.Duration
+
+	// ReissueRevoked triggers the reissue of revoked certificates by cert-manager.
+	ReissueRevoked bool
+
+	// Audit records every revocation and reissue. Nothing is recorded if nil.
+	Audit *audit.Logger
+}
+
+func NewRevocationReconciler(checker *provisioners.RevocationChecker) *RevocationReconciler {
+	return &RevocationReconciler{
+		checker: checker,
+	}
+}
+
+// SetupWithManager initializes the revocation controller into the controller runtime.
+func (r *RevocationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Check certificates when created and after each issuance. Otherwise, they are checked periodically.
+	filter := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isIssuedByDigicertIssuer(e.Object.(*cmapi.Certificate))
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCrt, newCrt := e.ObjectOld.(*cmapi.Certificate), e.ObjectNew.(*cmapi.Certificate)
+			if isIssuedByDigicertIssuer(oldCrt) && !isIssuedByDigicertIssuer(newCrt) {
+				return true
+			}
+			return isIssuedByDigicertIssuer(newCrt) && !newCrt.Status.NotBefore.Equal(oldCrt.Status.NotBefore)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isIssuedByDigicertIssuer(e.Object.(*cmapi.Certificate))
+		},
+	}
+
+	r.recorder = mgr.GetEventRecorderFor("revocationController")
+	r.log = mgr.GetLogger().WithName("controllers").WithName("Revocation")
+	r.Client = mgr.GetClient()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("certificate-revocation").
+		For(&cmapi.Certificate{}).
+		WithEventFilter(filter).
+		Complete(r)
+}
+
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificates/status,verbs=get;update;patch
+
+// Reconcile checks the revocation status of the certificate in the Secret of the Certificate.
+func (r *RevocationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.log.WithValues("certificate", req.NamespacedName)
+
+	crt := new(cmapi.Certificate)
+	if err := r.Client.Get(ctx, req.NamespacedName, crt); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteRevocationMetrics(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !isIssuedByDigicertIssuer(crt) {
+		deleteRevocationMetrics(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	secret := new(core.Secret)
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: crt.Namespace, Name: crt.Spec.SecretName}, secret); err != nil {
+		return ctrl.Result{RequeueAfter: r.CheckInterval}, client.IgnoreNotFound(err)
+	}
+	chain, err := provisioners.DecodeCertificates(append(append([]byte{}, secret.Data[core.TLSCertKey]...), secret.Data[cmmeta.TLSCAKey]...))
+	if err != nil || len(chain) == 0 {
+		log.V(4).Info("skipping secret without valid certificate", "secret", crt.Spec.SecretName)
+		return ctrl.Result{RequeueAfter: r.CheckInterval}, nil
+	}
+
+	result, err := r.checker.Check(ctx, chain)
+	if err != nil {
+		log.Info("failed to check revocation status", "error", err.Error())
+		metricRevocationCheckErrors.WithLabelValues(crt.Namespace, crt.Name).Inc()
+		return ctrl.Result{RequeueAfter: r.CheckInterval}, nil
+	}
+
+	if result.Status != provisioners.RevocationStatusRevoked {
+		if result.Status == provisioners.RevocationStatusGood {
+			metricCertificateRevoked.WithLabelValues(crt.Namespace, crt.Name).Set(0)
+		}
+		return ctrl.Result{RequeueAfter: r.CheckInterval}, nil
+	}
+
+	log.Info("certificate revoked", "secret", crt.Spec.SecretName, "revokedAt", result.RevokedAt, "reason", result.Reason, "source", result.Source)
+	metricCertificateRevoked.WithLabelValues(crt.Namespace, crt.Name).Set(1)
+	r.recorder.Eventf(crt, core.EventTypeWarning, "CertificateRevoked", "Certificate in Secret %s was revoked at %s with reason %d according to %s",
+		crt.Spec.SecretName, result.RevokedAt.UTC().Format(time.RFC3339), result.Reason, result.Source)
+
+	rec := audit.ForCertificate(audit.ActionRevocation, crt)
+	rec.Issuer = issuerLabel(crt.Namespace, crt.Spec.IssuerRef)
+	rec.Reason = fmt.Sprintf("revoked at %s with reason %d according to %s", result.RevokedAt.UTC().Format(time.RFC3339), result.Reason, result.Source)
+	r.Audit.Log(ctx, rec)
+
+	if r.ReissueRevoked {
+		if err := r.reissue(ctx, crt); err != nil {
+			log.Error(err, "failed to trigger reissue of revoked certificate")
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: r.CheckInterval}, nil
+}
+
+// reissue triggers the issuance of a new certificate the same way `cmctl renew` does.
+func (r *RevocationReconciler) reissue(ctx context.Context, crt *cmapi.Certificate) error {
+	if apiutil.CertificateHasCondition(crt, cmapi.CertificateCondition{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionTrue}) {
+		return nil
+	}
+
+	newCrt := crt.DeepCopy()
+	apiutil.SetCertificateCondition(newCrt, newCrt.Generation, cmapi.CertificateConditionIssuing, cmmeta.ConditionTrue, reasonRevoked,
+		"Reissuing revoked certificate")
+	if err := r.Client.Status().Patch(ctx, newCrt, client.MergeFrom(crt)); err != nil {
+		return err
+	}
+
+	r.recorder.Event(crt, core.EventTypeNormal, "Reissuing", "Triggered reissue of revoked certificate")
+
+	rec := audit.ForCertificate(audit.ActionReissue, crt)
+	rec.Issuer = issuerLabel(crt.Namespace, crt.Spec.IssuerRef)
+	rec.Reason = "Reissuing revoked certificate"
+	r.Audit.Log(ctx, rec)
+	return nil
+}
+
+// deleteRevocationMetrics removes the series of a Certificate that was deleted or is no longer issued by a DigicertIssuer.
+func deleteRevocationMetrics(crt types.NamespacedName) {
+	metricCertificateRevoked.DeleteLabelValues(crt.Namespace, crt.Name)
+	metricRevocationCheckErrors.DeleteLabelValues(crt.Namespace, crt.Name)
+}
+
+// isIssuedByDigicertIssuer returns true if the Certificate references a DigicertIssuer or ClusterDigicertIssuer.
+func isIssuedByDigicertIssuer(crt *cmapi.Certificate) bool {
+	return crt.Spec.IssuerRef.Group == certmanagerv1beta1.GroupVersion.Group
+}
@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRevocationReconcileDeletesMetrics(t *testing.T) {
+	tests := []struct {
+		name string
+		objs []client.Object
+	}{
+		{
+			name: "deleted certificate",
+		},
+		{
+			name: "certificate issued by another issuer",
+			objs: []client.Object{&cmapi.Certificate{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "example"},
+				Spec: cmapi.CertificateSpec{
+					SecretName: "example-tls",
+					IssuerRef:  cmmeta.ObjectReference{Group: "cert-manager.io", Kind: "ClusterIssuer", Name: "other"},
+				},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crt := types.NamespacedName{Namespace: "team", Name: "example"}
+			metricCertificateRevoked.WithLabelValues(crt.Namespace, crt.Name).Set(1)
+			metricRevocationCheckErrors.WithLabelValues(crt.Namespace, crt.Name).Inc()
+
+			r := NewRevocationReconciler(nil)
+			r.Client = fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(tt.objs...).Build()
+			r.log = logr.Discard()
+			if _, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: crt}); err != nil {
+				t.Fatalf("Reconcile returned error: %v", err)
+			}
+
+			if count := testutil.CollectAndCount(metricCertificateRevoked); count != 0 {
+				t.Fatalf("expected revoked series to be deleted, got %d series", count)
+			}
+			if count := testutil.CollectAndCount(metricRevocationCheckErrors); count != 0 {
+				t.Fatalf("expected check error series to be deleted, got %d series", count)
+			}
+		})
+	}
+}
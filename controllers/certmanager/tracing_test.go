@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "team",
+			Name:        "example",
+			UID:         "1234",
+			Annotations: map[string]string{annotationKeyOrderID: "42"},
+		},
+		Spec: cmapi.CertificateRequestSpec{
+			IssuerRef: cmmeta.ObjectReference{
+				Group: certmanagerv1beta1.GroupVersion.Group,
+				Kind:  certmanagerv1beta1.DigicertIssuerKind,
+				Name:  "missing",
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(newTestScheme(t)).WithObjects(cr).WithStatusSubresource(cr).Build()
+
+	crReconciler := &CertificateRequestReconciler{Client: c, log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+	// The issuer is missing, so the request is requeued with an error.
+	_, _ = crReconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team", Name: "example"}})
+
+	issuerReconciler := NewDigicertIssuerReconciler("")
+	issuerReconciler.Client = c
+	issuerReconciler.log = logr.Discard()
+	if _, err := issuerReconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team", Name: "missing"}}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	crSpan, ok := spans["CertificateRequestReconciler.Reconcile"]
+	if !ok {
+		t.Fatalf("expected span of the CertificateRequest reconcile, got %v", spans)
+	}
+	expectSpanAttribute(t, crSpan, attribute.String("k8s.namespace.name", "team"))
+	expectSpanAttribute(t, crSpan, attribute.String("certmanager.certificaterequest.name", "example"))
+	expectSpanAttribute(t, crSpan, tracing.AttributeCertificateRequestUID.String("1234"))
+	expectSpanAttribute(t, crSpan, tracing.AttributeOrderID.String("42"))
+
+	issuerSpan, ok := spans["DigicertIssuerReconciler.Reconcile"]
+	if !ok {
+		t.Fatalf("expected span of the DigicertIssuer reconcile, got %v", spans)
+	}
+	expectSpanAttribute(t, issuerSpan, tracing.AttributeIssuer.String("team/missing"))
+}
+
+func expectSpanAttribute(t *testing.T, span tracetest.SpanStub, expected attribute.KeyValue) {
+	t.Helper()
+	for _, attr := range span.Attributes {
+		if attr.Key == expected.Key {
+			if attr.Value != expected.Value {
+				t.Fatalf("unexpected value of attribute %s on span %q, got=%v expected=%v", expected.Key, span.Name, attr.Value.Emit(), expected.Value.Emit())
+			}
+			return
+		}
+	}
+	t.Fatalf("expected attribute %s on span %q", expected.Key, span.Name)
+}
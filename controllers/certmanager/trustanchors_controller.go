@@ -0,0 +1,465 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// trustAnchorsResyncInterval removes expired certificates from the published trust anchors.
+	trustAnchorsResyncInterval = time.Hour
+	// trustAnchorsDebounce collects the certificates issued in short succession into a single rebuild of the trust anchors.
+	trustAnchorsDebounce = time.Minute
+
+	// trustAnchorsFinalizer removes the ConfigMap from the trust-manager Bundle before the issuer is deleted.
+	trustAnchorsFinalizer = "certmanager.cloud.sap/trust-anchors"
+	// labelKeyTrustAnchors marks the ConfigMaps published by the TrustAnchorsReconciler.
+	labelKeyTrustAnchors = "certmanager.cloud.sap/trust-anchors"
+	// annotationKeyTrustManagerBundle is the trust-manager Bundle the ConfigMap was added to.
+	annotationKeyTrustManagerBundle = "certmanager.cloud.sap/trust-manager-bundle"
+	// annotationKeyCreatedBy marks the trust-manager Bundles created by the TrustAnchorsReconciler.
+	// They are deleted once their last source was removed.
+	annotationKeyCreatedBy  = "certmanager.cloud.sap/created-by"
+	createdByDigicertIssuer = "digicert-issuer"
+
+	// DefaultTrustNamespace is the default trust namespace of trust-manager.
+	DefaultTrustNamespace = "cert-manager"
+
+	trustAnchorsConfigMapSuffix  = "-trust-anchors"
+	trustAnchorsKeyCABundle      = "ca-bundle.pem"
+	trustAnchorsKeyRoots         = "roots.pem"
+	trustAnchorsKeyIntermediates = "intermediates.pem"
+	trustAnchorsKeyPKCS7         = "ca-bundle.p7b"
+)
+
+var trustManagerBundleGVK = schema.GroupVersionKind{Group: "trust.cert-manager.io", Version: "v1alpha1", Kind: "Bundle"}
+
+// TrustAnchorsReconciler publishes the roots and intermediates of certificates issued by a DigicertIssuer.
+type TrustAnchorsReconciler struct {
+	client.Client
+	log                    logr.Logger
+	recorder               record.EventRecorder
+	clusterIssuerNamespace string
+
+	// DefaultProviderNamespace is the namespace of DigicertIssuers used by CertificateRequests of all namespaces.
+	DefaultProviderNamespace string
+
+	// BackoffDurationProvisionerNotReady is the backoff duration if the provisioner is not ready.
+	BackoffDurationProvisionerNotReady time.Duration
+
+	// TrustNamespace is the namespace trust-manager reads Bundle sources from.
+	// ConfigMaps in other namespaces are not added to trust-manager Bundles.
+	TrustNamespace string
+}
+
+func NewTrustAnchorsReconciler(clusterIssuerNamespace string) *TrustAnchorsReconciler {
+	return &TrustAnchorsReconciler{
+		clusterIssuerNamespace: clusterIssuerNamespace,
+		TrustNamespace:         DefaultTrustNamespace,
+	}
+}
+
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=trust.cert-manager.io,resources=bundles,verbs=get;create;update;patch;delete
+
+func (r *TrustAnchorsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var (
+		issuer     client.Object
+		issuerSpec certmanagerv1beta1.DigicertIssuerSpec
+		namespace  = req.Namespace
+	)
+	if req.Namespace == "" {
+		iss := new(certmanagerv1beta1.ClusterDigicertIssuer)
+		if err := r.Client.Get(ctx, req.NamespacedName, iss); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		issuer, issuerSpec, namespace = iss, iss.Spec, r.clusterIssuerNamespace
+	} else {
+		iss := new(certmanagerv1beta1.DigicertIssuer)
+		if err := r.Client.Get(ctx, req.NamespacedName, iss); err != nil {
+			return ctrl.Result{}, client.IgnoreNotFound(err)
+		}
+		issuer, issuerSpec = iss, iss.Spec
+	}
+	logger := r.log.WithValues("issuer", req.NamespacedName)
+
+	// Stop managing the ConfigMap and Bundle source if the issuer is deleted or the trust anchors are unset.
+	if !issuer.GetDeletionTimestamp().IsZero() || issuerSpec.TrustAnchors == nil {
+		if err := r.cleanup(ctx, issuer, namespace, "", ""); err != nil {
+			logger.Error(err, "failed to remove trust anchors")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.updateFinalizer(ctx, issuer, false)
+	}
+
+	configMapName := issuerSpec.TrustAnchors.ConfigMapName
+	if configMapName == "" {
+		configMapName = req.Name + trustAnchorsConfigMapSuffix
+	}
+	bundleName := issuerSpec.TrustAnchors.TrustManagerBundle
+	if bundleName != "" && namespace != r.TrustNamespace {
+		r.recorder.Eventf(issuer, core.EventTypeWarning, "TrustAnchorsInvalid",
+			"Not adding ConfigMap %s/%s to trust-manager Bundle %s, as trust-manager only reads sources from the trust namespace %s",
+			namespace, configMapName, bundleName, r.TrustNamespace)
+		bundleName = ""
+	}
+
+	// Remove ConfigMaps and Bundle sources of previous settings.
+	if err := r.cleanup(ctx, issuer, namespace, configMapName, bundleName); err != nil {
+		logger.Error(err, "failed to remove outdated trust anchors")
+		return ctrl.Result{}, err
+	}
+	if err := r.updateFinalizer(ctx, issuer, bundleName != ""); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	provisioner, ok := provisioners.Load(req.NamespacedName)
+	if !ok {
+		logger.V(4).Info("provisioner not found, waiting for issuer to become ready")
+		return ctrl.Result{RequeueAfter: r.BackoffDurationProvisionerNotReady}, nil
+	}
+
+	chains, err := r.listIssuedChains(ctx, req.NamespacedName)
+	if err != nil {
+		logger.Error(err, "failed to list certificate requests")
+		return ctrl.Result{}, err
+	}
+
+	anchors := provisioner.CollectTrustAnchors(ctx, chains, time.Now())
+	if len(anchors.All()) == 0 {
+		logger.V(4).Info("no certificates issued yet, skipping trust anchors")
+		return ctrl.Result{RequeueAfter: trustAnchorsResyncInterval}, nil
+	}
+
+	if err := r.publishConfigMap(ctx, issuer, namespace, configMapName, bundleName, anchors); err != nil {
+		logger.Error(err, "failed to publish trust anchors", "configMap", configMapName)
+		r.recorder.Eventf(issuer, core.EventTypeWarning, "TrustAnchorsFailed", "Failed to publish trust anchors to ConfigMap %s/%s: %v", namespace, configMapName, err)
+		return ctrl.Result{}, err
+	}
+
+	if bundleName != "" {
+		if err := r.ensureBundleSource(ctx, bundleName, configMapName); err != nil {
+			logger.Error(err, "failed to add trust anchors to trust-manager bundle", "bundle", bundleName)
+			r.recorder.Eventf(issuer, core.EventTypeWarning, "TrustAnchorsFailed", "Failed to add ConfigMap %s to trust-manager Bundle %s: %v", configMapName, bundleName, err)
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: trustAnchorsResyncInterval}, nil
+}
+
+// listIssuedChains returns the certificate chains of the CertificateRequests issued by the issuer.
+// DigicertIssuers in the default provider namespace issue for CertificateRequests of all namespaces.
+func (r *TrustAnchorsReconciler) listIssuedChains(ctx context.Context, issuer types.NamespacedName) ([][]*x509.Certificate, error) {
+	var opts []client.ListOption
+	if issuer.Namespace != "" && issuer.Namespace != r.DefaultProviderNamespace {
+		opts = append(opts, client.InNamespace(issuer.Namespace))
+	}
+
+	crList := new(cmapi.CertificateRequestList)
+	if err := r.Client.List(ctx, crList, opts...); err != nil {
+		return nil, err
+	}
+
+	chains := make([][]*x509.Certificate, 0)
+	for idx := range crList.Items {
+		cr := &crList.Items[idx]
+		if len(cr.Status.Certificate) == 0 || !isIssuedBy(cr, issuer) {
+			continue
+		}
+
+		chain, err := provisioners.DecodeCertificates(append(append([]byte{}, cr.Status.Certificate...), cr.Status.CA...))
+		if err != nil {
+			r.log.V(4).Info("skipping certificate request with invalid certificate", "namespace", cr.Namespace, "name", cr.Name, "error", err.Error())
+			continue
+		}
+		chains = append(chains, chain)
+	}
+	return chains, nil
+}
+
+func (r *TrustAnchorsReconciler) publishConfigMap(ctx context.Context, issuer client.Object, namespace, name, bundleName string, anchors provisioners.TrustAnchors) error {
+	bundlePEM, err := provisioners.EncodePEM(anchors.All())
+	if err != nil {
+		return err
+	}
+	rootsPEM, err := provisioners.EncodePEM(anchors.Roots)
+	if err != nil {
+		return err
+	}
+	intermediatesPEM, err := provisioners.EncodePEM(anchors.Intermediates)
+	if err != nil {
+		return err
+	}
+	pkcs7, err := provisioners.EncodePKCS7(anchors.All())
+	if err != nil {
+		return err
+	}
+
+	cm := &core.ConfigMap{ObjectMeta: ctrl.ObjectMeta{Namespace: namespace, Name: name}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		metav1.SetMetaDataLabel(&cm.ObjectMeta, labelKeyTrustAnchors, "true")
+		metav1.SetMetaDataLabel(&cm.ObjectMeta, LabelKeyConfigMap, "true")
+		if bundleName != "" {
+			metav1.SetMetaDataAnnotation(&cm.ObjectMeta, annotationKeyTrustManagerBundle, bundleName)
+		} else {
+			delete(cm.Annotations, annotationKeyTrustManagerBundle)
+		}
+		cm.Data = map[string]string{
+			trustAnchorsKeyCABundle:      string(bundlePEM),
+			trustAnchorsKeyRoots:         string(rootsPEM),
+			trustAnchorsKeyIntermediates: string(intermediatesPEM),
+		}
+		cm.BinaryData = map[string][]byte{
+			trustAnchorsKeyPKCS7: pkcs7,
+		}
+		return controllerutil.SetControllerReference(issuer, cm, r.Client.Scheme())
+	})
+	if err != nil {
+		return err
+	}
+
+	if op != controllerutil.OperationResultNone {
+		r.log.Info("published trust anchors", "namespace", namespace, "name", name, "roots", len(anchors.Roots), "intermediates", len(anchors.Intermediates))
+		r.recorder.Eventf(issuer, core.EventTypeNormal, "TrustAnchorsUpdated", "Published %d roots and %d intermediates to ConfigMap %s/%s",
+			len(anchors.Roots), len(anchors.Intermediates), namespace, name)
+	}
+	return nil
+}
+
+// ensureBundleSource adds the ConfigMap as source to the trust-manager Bundle, creating the Bundle if missing.
+func (r *TrustAnchorsReconciler) ensureBundleSource(ctx context.Context, bundleName, configMapName string) error {
+	bundle := new(unstructured.Unstructured)
+	bundle.SetGroupVersionKind(trustManagerBundleGVK)
+	bundle.SetName(bundleName)
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, bundle, func() error {
+		if bundle.GetResourceVersion() == "" {
+			annotations := bundle.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[annotationKeyCreatedBy] = createdByDigicertIssuer
+			bundle.SetAnnotations(annotations)
+		}
+
+		sources, _, err := unstructured.NestedSlice(bundle.Object, "spec", "sources")
+		if err != nil {
+			return err
+		}
+		for _, source := range sources {
+			name, _, _ := unstructured.NestedString(asMap(source), "configMap", "name")
+			key, _, _ := unstructured.NestedString(asMap(source), "configMap", "key")
+			if name == configMapName && key == trustAnchorsKeyCABundle {
+				return nil
+			}
+		}
+
+		sources = append(sources, map[string]interface{}{
+			"configMap": map[string]interface{}{"name": configMapName, "key": trustAnchorsKeyCABundle},
+		})
+		if err := unstructured.SetNestedSlice(bundle.Object, sources, "spec", "sources"); err != nil {
+			return err
+		}
+		if _, ok, _ := unstructured.NestedMap(bundle.Object, "spec", "target"); !ok {
+			return unstructured.SetNestedMap(bundle.Object, map[string]interface{}{
+				"configMap": map[string]interface{}{"key": trustAnchorsKeyCABundle},
+			}, "spec", "target")
+		}
+		return nil
+	})
+	return err
+}
+
+// removeBundleSource removes the ConfigMap from the sources of the trust-manager Bundle.
+// Bundles created by the TrustAnchorsReconciler are deleted with their last source.
+func (r *TrustAnchorsReconciler) removeBundleSource(ctx context.Context, bundleName, configMapName string) error {
+	bundle := new(unstructured.Unstructured)
+	bundle.SetGroupVersionKind(trustManagerBundleGVK)
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: bundleName}, bundle); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	sources, _, err := unstructured.NestedSlice(bundle.Object, "spec", "sources")
+	if err != nil {
+		return err
+	}
+	remaining := make([]interface{}, 0, len(sources))
+	for _, source := range sources {
+		name, _, _ := unstructured.NestedString(asMap(source), "configMap", "name")
+		key, _, _ := unstructured.NestedString(asMap(source), "configMap", "key")
+		if name != configMapName || key != trustAnchorsKeyCABundle {
+			remaining = append(remaining, source)
+		}
+	}
+	if len(remaining) == len(sources) {
+		return nil
+	}
+
+	if len(remaining) == 0 && bundle.GetAnnotations()[annotationKeyCreatedBy] == createdByDigicertIssuer {
+		r.log.Info("deleting trust-manager bundle without sources", "bundle", bundleName)
+		return client.IgnoreNotFound(r.Client.Delete(ctx, bundle))
+	}
+	if err := unstructured.SetNestedSlice(bundle.Object, remaining, "spec", "sources"); err != nil {
+		return err
+	}
+	r.log.Info("removed trust anchors from trust-manager bundle", "bundle", bundleName, "configMap", configMapName)
+	return r.Client.Update(ctx, bundle)
+}
+
+// cleanup deletes the ConfigMaps published for the issuer except the one to keep,
+// and removes them from trust-manager Bundles other than the one to keep.
+func (r *TrustAnchorsReconciler) cleanup(ctx context.Context, issuer client.Object, namespace, keepConfigMap, keepBundle string) error {
+	cmList := new(core.ConfigMapList)
+	if err := r.Client.List(ctx, cmList, client.InNamespace(namespace), client.MatchingLabels{labelKeyTrustAnchors: "true"}); err != nil {
+		return err
+	}
+
+	for idx := range cmList.Items {
+		cm := &cmList.Items[idx]
+		if !metav1.IsControlledBy(cm, issuer) {
+			continue
+		}
+		if bundleName := cm.Annotations[annotationKeyTrustManagerBundle]; bundleName != "" && (cm.Name != keepConfigMap || bundleName != keepBundle) {
+			if err := r.removeBundleSource(ctx, bundleName, cm.Name); err != nil {
+				return err
+			}
+		}
+		if cm.Name != keepConfigMap {
+			r.log.Info("deleting trust anchors", "namespace", cm.Namespace, "name", cm.Name)
+			if err := r.Client.Delete(ctx, cm); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// updateFinalizer adds or removes the finalizer cleaning up the trust-manager Bundle source.
+func (r *TrustAnchorsReconciler) updateFinalizer(ctx context.Context, issuer client.Object, present bool) error {
+	original := issuer.DeepCopyObject().(client.Object)
+	var changed bool
+	if present {
+		changed = controllerutil.AddFinalizer(issuer, trustAnchorsFinalizer)
+	} else {
+		changed = controllerutil.RemoveFinalizer(issuer, trustAnchorsFinalizer)
+	}
+	if !changed {
+		return nil
+	}
+	return r.Client.Patch(ctx, issuer, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+func asMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+// isIssuedBy returns true if the CertificateRequest references the issuer.
+func isIssuedBy(cr *cmapi.CertificateRequest, issuer types.NamespacedName) bool {
+	ref := cr.Spec.IssuerRef
+	if ref.Group != certmanagerv1beta1.GroupVersion.Group || ref.Name != issuer.Name {
+		return false
+	}
+	isClusterIssuer := strings.EqualFold(ref.Kind, certmanagerv1beta1.ClusterDigicertIssuerKind)
+	return isClusterIssuer == (issuer.Namespace == "")
+}
+
+// issuedCertificateRequestHandler enqueues the issuer of a newly issued CertificateRequest after trustAnchorsDebounce.
+// Requests issued in short succession are collected into a single rebuild of the trust anchors.
+func (r *TrustAnchorsReconciler) issuedCertificateRequestHandler() handler.EventHandler {
+	enqueue := func(obj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+		for _, req := range r.issuerForCertificateRequest(obj) {
+			q.AddAfter(req, trustAnchorsDebounce)
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(e.Object, q)
+		},
+		UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			if oldCR, ok := e.ObjectOld.(*cmapi.CertificateRequest); ok && len(oldCR.Status.Certificate) > 0 {
+				return
+			}
+			enqueue(e.ObjectNew, q)
+		},
+	}
+}
+
+// issuerForCertificateRequest returns the issuer of an issued CertificateRequest.
+func (r *TrustAnchorsReconciler) issuerForCertificateRequest(obj client.Object) []reconcile.Request {
+	cr, ok := obj.(*cmapi.CertificateRequest)
+	if !ok || len(cr.Status.Certificate) == 0 || cr.Spec.IssuerRef.Group != certmanagerv1beta1.GroupVersion.Group {
+		return nil
+	}
+
+	// Each reconciler only handles either DigicertIssuers or ClusterDigicertIssuers.
+	isClusterIssuer := strings.EqualFold(cr.Spec.IssuerRef.Kind, certmanagerv1beta1.ClusterDigicertIssuerKind)
+	if isClusterIssuer != (r.clusterIssuerNamespace != "") {
+		return nil
+	}
+	if isClusterIssuer {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: cr.Spec.IssuerRef.Name}}}
+	}
+	requests := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: cr.Namespace, Name: cr.Spec.IssuerRef.Name}}}
+	if r.DefaultProviderNamespace != "" && r.DefaultProviderNamespace != cr.Namespace {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: r.DefaultProviderNamespace, Name: cr.Spec.IssuerRef.Name}})
+	}
+	return requests
+}
+
+// SetupWithManager reconciles the trust anchors when the spec of the issuer changes, but not on status updates like
+// the quota usage patched for every order. Changes of the published ConfigMap are reverted.
+func (r *TrustAnchorsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("digicertIssuerTrustAnchors")
+	r.log = mgr.GetLogger().WithName("controllers").WithName("DigicertIssuerTrustAnchors")
+	r.Client = mgr.GetClient()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("digicertissuer-trustanchors").
+		For(&certmanagerv1beta1.DigicertIssuer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&core.ConfigMap{}).
+		Watches(&cmapi.CertificateRequest{}, r.issuedCertificateRequestHandler()).
+		Complete(r)
+}
+
+func (r *TrustAnchorsReconciler) SetupWithManagerClusterIssuer(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("clusterDigicertIssuerTrustAnchors")
+	r.log = mgr.GetLogger().WithName("controllers").WithName("ClusterDigicertIssuerTrustAnchors")
+	r.Client = mgr.GetClient()
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("clusterdigicertissuer-trustanchors").
+		For(&certmanagerv1beta1.ClusterDigicertIssuer{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&core.ConfigMap{}).
+		Watches(&cmapi.CertificateRequest{}, r.issuedCertificateRequestHandler()).
+		Complete(r)
+}
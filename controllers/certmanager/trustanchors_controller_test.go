@@ -0,0 +1,276 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package certmanager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	certmanagerv1beta1 "github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/provisioners"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{clientgoscheme.AddToScheme, cmapi.AddToScheme, certmanagerv1beta1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatalf("failed to build scheme: %v", err)
+		}
+	}
+	return scheme
+}
+
+// newTestChain returns the PEM encoded leaf certificate and self-signed root issuing it.
+func newTestChain(t *testing.T) (leafPEM, rootPEM []byte) {
+	t.Helper()
+	rootKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Root CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("failed to create root: %v", err)
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		t.Fatalf("failed to parse root: %v", err)
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(12 * time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, root, &leafKey.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("failed to create leaf: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rootDER})
+}
+
+func newTrustAnchorsTest(t *testing.T, namespace string, trustAnchors *certmanagerv1beta1.TrustAnchors, objs ...client.Object) (*TrustAnchorsReconciler, *record.FakeRecorder, types.NamespacedName) {
+	t.Helper()
+	issuerName := types.NamespacedName{Namespace: namespace, Name: strings.ToLower(strings.ReplaceAll(t.Name(), "/", "-"))}
+	provisioners.Store(issuerName, &provisioners.CertCentral{})
+
+	leafPEM, rootPEM := newTestChain(t)
+	issuer := &certmanagerv1beta1.DigicertIssuer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: issuerName.Namespace, Name: issuerName.Name},
+		Spec:       certmanagerv1beta1.DigicertIssuerSpec{TrustAnchors: trustAnchors},
+	}
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Namespace: issuerName.Namespace, Name: "example"},
+		Spec: cmapi.CertificateRequestSpec{
+			IssuerRef: cmmeta.ObjectReference{
+				Group: certmanagerv1beta1.GroupVersion.Group,
+				Kind:  certmanagerv1beta1.DigicertIssuerKind,
+				Name:  issuerName.Name,
+			},
+		},
+		Status: cmapi.CertificateRequestStatus{Certificate: leafPEM, CA: rootPEM},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	r := NewTrustAnchorsReconciler("")
+	r.Client = fake.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(append([]client.Object{issuer, cr}, objs...)...).
+		Build()
+	r.log = logr.Discard()
+	r.recorder = recorder
+	return r, recorder, issuerName
+}
+
+func getBundle(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	t.Helper()
+	bundle := new(unstructured.Unstructured)
+	bundle.SetGroupVersionKind(trustManagerBundleGVK)
+	if err := c.Get(context.Background(), types.NamespacedName{Name: name}, bundle); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		t.Fatalf("failed to get bundle: %v", err)
+	}
+	return bundle
+}
+
+func TestTrustAnchorsReconcile(t *testing.T) {
+	ctx := context.Background()
+	r, _, issuerName := newTrustAnchorsTest(t, DefaultTrustNamespace, &certmanagerv1beta1.TrustAnchors{TrustManagerBundle: "digicert"})
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: issuerName}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	cm := new(core.ConfigMap)
+	cmName := types.NamespacedName{Namespace: issuerName.Namespace, Name: issuerName.Name + trustAnchorsConfigMapSuffix}
+	if err := r.Client.Get(ctx, cmName, cm); err != nil {
+		t.Fatalf("expected trust anchors ConfigMap: %v", err)
+	}
+	if !strings.Contains(cm.Data[trustAnchorsKeyRoots], "BEGIN CERTIFICATE") {
+		t.Fatalf("expected root in ConfigMap, got %q", cm.Data[trustAnchorsKeyRoots])
+	}
+	if cm.Annotations[annotationKeyTrustManagerBundle] != "digicert" {
+		t.Fatalf("expected ConfigMap to reference bundle, got annotations %v", cm.Annotations)
+	}
+	if cm.Labels[LabelKeyConfigMap] != "true" {
+		t.Fatalf("expected ConfigMap to be labelled for the cache, got labels %v", cm.Labels)
+	}
+
+	bundle := getBundle(t, r.Client, "digicert")
+	if bundle == nil {
+		t.Fatal("expected trust-manager bundle to be created")
+	}
+	sources, _, _ := unstructured.NestedSlice(bundle.Object, "spec", "sources")
+	if len(sources) != 1 {
+		t.Fatalf("expected 1 bundle source, got %v", sources)
+	}
+
+	issuer := new(certmanagerv1beta1.DigicertIssuer)
+	if err := r.Client.Get(ctx, issuerName, issuer); err != nil {
+		t.Fatalf("failed to get issuer: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(issuer, trustAnchorsFinalizer) {
+		t.Fatalf("expected finalizer, got %v", issuer.Finalizers)
+	}
+
+	// Unsetting the trust anchors removes the ConfigMap, the created Bundle and the finalizer.
+	issuer.Spec.TrustAnchors = nil
+	if err := r.Client.Update(ctx, issuer); err != nil {
+		t.Fatalf("failed to update issuer: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: issuerName}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	if err := r.Client.Get(ctx, cmName, cm); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected ConfigMap to be deleted, got %v", err)
+	}
+	if bundle := getBundle(t, r.Client, "digicert"); bundle != nil {
+		t.Fatalf("expected created bundle to be deleted, got %v", bundle.Object)
+	}
+	if err := r.Client.Get(ctx, issuerName, issuer); err != nil {
+		t.Fatalf("failed to get issuer: %v", err)
+	}
+	if controllerutil.ContainsFinalizer(issuer, trustAnchorsFinalizer) {
+		t.Fatalf("expected finalizer to be removed, got %v", issuer.Finalizers)
+	}
+}
+
+func TestTrustAnchorsReconcileKeepsForeignBundle(t *testing.T) {
+	ctx := context.Background()
+	bundle := new(unstructured.Unstructured)
+	bundle.SetGroupVersionKind(trustManagerBundleGVK)
+	bundle.SetName("shared")
+	if err := unstructured.SetNestedSlice(bundle.Object, []interface{}{
+		map[string]interface{}{"useDefaultCAs": true},
+	}, "spec", "sources"); err != nil {
+		t.Fatalf("failed to build bundle: %v", err)
+	}
+	r, _, issuerName := newTrustAnchorsTest(t, DefaultTrustNamespace, &certmanagerv1beta1.TrustAnchors{TrustManagerBundle: "shared"}, bundle)
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: issuerName}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	sources, _, _ := unstructured.NestedSlice(getBundle(t, r.Client, "shared").Object, "spec", "sources")
+	if len(sources) != 2 {
+		t.Fatalf("expected 2 bundle sources, got %v", sources)
+	}
+
+	// Moving to another ConfigMap removes the previous one from the Bundle, which is kept as it was not created by us.
+	issuer := new(certmanagerv1beta1.DigicertIssuer)
+	if err := r.Client.Get(ctx, issuerName, issuer); err != nil {
+		t.Fatalf("failed to get issuer: %v", err)
+	}
+	issuer.Spec.TrustAnchors.ConfigMapName = "renamed"
+	issuer.Spec.TrustAnchors.TrustManagerBundle = ""
+	if err := r.Client.Update(ctx, issuer); err != nil {
+		t.Fatalf("failed to update issuer: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: issuerName}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	shared := getBundle(t, r.Client, "shared")
+	if shared == nil {
+		t.Fatal("expected foreign bundle to be kept")
+	}
+	sources, _, _ = unstructured.NestedSlice(shared.Object, "spec", "sources")
+	if len(sources) != 1 {
+		t.Fatalf("expected only the foreign bundle source, got %v", sources)
+	}
+	oldName := types.NamespacedName{Namespace: issuerName.Namespace, Name: issuerName.Name + trustAnchorsConfigMapSuffix}
+	if err := r.Client.Get(ctx, oldName, new(core.ConfigMap)); !apierrors.IsNotFound(err) {
+		t.Fatalf("expected previous ConfigMap to be deleted, got %v", err)
+	}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: issuerName.Namespace, Name: "renamed"}, new(core.ConfigMap)); err != nil {
+		t.Fatalf("expected renamed ConfigMap: %v", err)
+	}
+}
+
+func TestTrustAnchorsReconcileOutsideTrustNamespace(t *testing.T) {
+	ctx := context.Background()
+	r, recorder, issuerName := newTrustAnchorsTest(t, "team", &certmanagerv1beta1.TrustAnchors{TrustManagerBundle: "digicert"})
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: issuerName}); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: "team", Name: issuerName.Name + trustAnchorsConfigMapSuffix}, new(core.ConfigMap)); err != nil {
+		t.Fatalf("expected trust anchors ConfigMap: %v", err)
+	}
+	if bundle := getBundle(t, r.Client, "digicert"); bundle != nil {
+		t.Fatalf("expected no bundle outside the trust namespace, got %v", bundle.Object)
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, "TrustAnchorsInvalid") {
+			t.Fatalf("unexpected event %q", e)
+		}
+	default:
+		t.Fatal("expected warning event")
+	}
+}
@@ -0,0 +1,232 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package audit writes a structured audit log of the certificates ordered with and revoked by DigiCert.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Action is the audited action.
+type Action string
+
+const (
+	// ActionOrder is an order submitted to CertCentral.
+	ActionOrder Action = "order"
+	// ActionReissue is a reissue of a Certificate triggered by the digicert-issuer.
+	ActionReissue Action = "reissue"
+	// ActionRevocation is the revocation of an issued certificate.
+	ActionRevocation Action = "revocation"
+	// ActionDenial is a CertificateRequest or certificate rejected by the digicert-issuer.
+	ActionDenial Action = "denial"
+)
+
+// StdoutDestination is the destination writing the audit log to stdout.
+const StdoutDestination = "stdout"
+
+const webhookTimeout = 10 * time.Second
+
+func init() {
+	metrics.Registry.MustRegister(metricWriteErrors)
+}
+
+var metricWriteErrors = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "digicertissuer_audit_log_errors_total",
+		Help: "Number of audit records that could not be written",
+	},
+)
+
+// Record is an entry of the audit log.
+// It deliberately has no field for credentials, so the CertCentral API token is never logged.
+type Record struct {
+	Time               time.Time `json:"time"`
+	Action             Action    `json:"action"`
+	Namespace          string    `json:"namespace"`
+	Certificate        string    `json:"certificate,omitempty"`
+	CertificateRequest string    `json:"certificateRequest,omitempty"`
+	Issuer             string    `json:"issuer,omitempty"`
+	Username           string    `json:"username,omitempty"`
+	Groups             []string  `json:"groups,omitempty"`
+	SANs               []string  `json:"sans,omitempty"`
+	OrderType          string    `json:"orderType,omitempty"`
+	OrderID            int       `json:"orderID,omitempty"`
+	Price              float64   `json:"price,omitempty"`
+	Currency           string    `json:"currency,omitempty"`
+	Reason             string    `json:"reason,omitempty"`
+}
+
+// ForCertificateRequest returns a record with the requester and the SANs of the CertificateRequest.
+func ForCertificateRequest(action Action, cr *cmapi.CertificateRequest) Record {
+	rec := Record{
+		Action:             action,
+		Namespace:          cr.Namespace,
+		Certificate:        cr.GetAnnotations()[cmapi.CertificateNameKey],
+		CertificateRequest: cr.Name,
+		Username:           cr.Spec.Username,
+		Groups:             cr.Spec.Groups,
+	}
+
+	block, _ := pem.Decode(cr.Spec.Request)
+	if block == nil {
+		return rec
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return rec
+	}
+	rec.SANs = append(rec.SANs, csr.DNSNames...)
+	for _, ip := range csr.IPAddresses {
+		rec.SANs = append(rec.SANs, ip.String())
+	}
+	return rec
+}
+
+// ForCertificate returns a record with the SANs of the Certificate.
+func ForCertificate(action Action, crt *cmapi.Certificate) Record {
+	rec := Record{
+		Action:      action,
+		Namespace:   crt.Namespace,
+		Certificate: crt.Name,
+	}
+	rec.SANs = append(rec.SANs, crt.Spec.DNSNames...)
+	rec.SANs = append(rec.SANs, crt.Spec.IPAddresses...)
+	return rec
+}
+
+// Logger writes audit records as JSON lines. A nil Logger discards all records.
+type Logger struct {
+	sink sink
+	log  logr.Logger
+	now  func() time.Time
+}
+
+type sink interface {
+	write(ctx context.Context, line []byte) error
+	close() error
+}
+
+// New returns a Logger writing to the destination, which is either "stdout", the URL of a webhook receiving
+// each record in a POST request, or the path of a file the records are appended to.
+// Returns nil if the destination is empty.
+func New(destination string) (*Logger, error) {
+	var s sink
+	switch u, err := url.Parse(destination); {
+	case destination == "":
+		return nil, nil
+	case destination == StdoutDestination:
+		s = &writerSink{w: os.Stdout}
+	case err == nil && (u.Scheme == "http" || u.Scheme == "https"):
+		s = &webhookSink{url: destination, client: &http.Client{Timeout: webhookTimeout}}
+	default:
+		f, err := os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		s = &writerSink{w: f, closer: f}
+	}
+
+	return &Logger{
+		sink: s,
+		log:  ctrl.Log.WithName("audit"),
+		now:  time.Now,
+	}, nil
+}
+
+// Log writes the record. Failures are logged but do not fail the audited action.
+func (l *Logger) Log(ctx context.Context, rec Record) {
+	if l == nil {
+		return
+	}
+	if rec.Time.IsZero() {
+		rec.Time = l.now().UTC()
+	}
+
+	line, err := json.Marshal(rec)
+	if err == nil {
+		err = l.sink.write(ctx, append(line, '\n'))
+	}
+	if err != nil {
+		metricWriteErrors.Inc()
+		l.log.Error(err, "failed to write audit record", "action", rec.Action, "namespace", rec.Namespace,
+			"certificate", rec.Certificate, "orderID", rec.OrderID)
+	}
+}
+
+// Close closes the destination of the audit log.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.sink.close()
+}
+
+// writerSink writes records to stdout or a file.
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+func (s *writerSink) write(_ context.Context, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *writerSink) close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// webhookSink posts each record to a webhook. Credentials can be given as user info of the URL.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *webhookSink) write(ctx context.Context, line []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(line))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sapcc/digicert-issuer")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook responded with %s", res.Status)
+	}
+	return nil
+}
+
+func (s *webhookSink) close() error {
+	return nil
+}
@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestForCertificateRequest(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "example.com"},
+		DNSNames:    []string{"example.com", "www.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+	}, key)
+	if err != nil {
+		t.Fatalf("create CSR: %v", err)
+	}
+
+	cr := &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "example-1",
+			Namespace:   "team-a",
+			Annotations: map[string]string{cmapi.CertificateNameKey: "example"},
+		},
+		Spec: cmapi.CertificateRequestSpec{
+			Request:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}),
+			Username: "system:serviceaccount:cert-manager:cert-manager",
+			Groups:   []string{"system:serviceaccounts"},
+		},
+	}
+
+	expected := Record{
+		Action:             ActionOrder,
+		Namespace:          "team-a",
+		Certificate:        "example",
+		CertificateRequest: "example-1",
+		Username:           "system:serviceaccount:cert-manager:cert-manager",
+		Groups:             []string{"system:serviceaccounts"},
+		SANs:               []string{"example.com", "www.example.com", "192.0.2.1"},
+	}
+	if got := ForCertificateRequest(ActionOrder, cr); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected record, got=%+v expected=%+v", got, expected)
+	}
+}
+
+func TestLoggerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := New(path)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	logger.Log(context.Background(), Record{Action: ActionOrder, Namespace: "team-a", OrderID: 42, Price: 218.5, Currency: "USD"})
+	logger.Log(context.Background(), Record{Action: ActionRevocation, Namespace: "team-b", Reason: "keyCompromise"})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open audit log: %v", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("audit log line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	if records[0].OrderID != 42 || records[0].Price != 218.5 || records[0].Time.IsZero() {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	if records[1].Action != ActionRevocation {
+		t.Fatalf("unexpected second record %+v", records[1])
+	}
+}
+
+func TestLoggerWebhook(t *testing.T) {
+	received := make(chan Record, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "audit" || password != "secret" {
+			t.Errorf("unexpected credentials %q:%q", user, password)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		var rec Record
+		if err := json.Unmarshal(body, &rec); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		received <- rec
+	}))
+	defer server.Close()
+
+	u := "http://audit:secret@" + server.Listener.Addr().String() + "/records"
+	logger, err := New(u)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	logger.Log(context.Background(), Record{Action: ActionDenial, Namespace: "team-a", Reason: "not allowed"})
+	if rec := <-received; rec.Action != ActionDenial || rec.Reason != "not allowed" {
+		t.Fatalf("unexpected record %+v", rec)
+	}
+}
+
+func TestLoggerNil(t *testing.T) {
+	logger, err := New("")
+	if err != nil || logger != nil {
+		t.Fatalf("expected no logger without destination, got %v, %v", logger, err)
+	}
+	logger.Log(context.Background(), Record{Action: ActionOrder})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package k8sutils
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func GetConfigMapData(ctx context.Context, k8sClient client.Reader, configMapNamespace, configMapName string) (map[string]string, error) {
+	cm := new(corev1.ConfigMap)
+	if err := k8sClient.Get(ctx, client.ObjectKey{
+		Namespace: configMapNamespace,
+		Name:      configMapName,
+	}, cm); err != nil {
+		return nil, err
+	}
+
+	if cm.Data == nil {
+		return nil, fmt.Errorf("configmap %s/%s is empty", configMapNamespace, configMapName)
+	}
+
+	return cm.Data, nil
+}
@@ -29,8 +29,9 @@ import (
 )
 
 type Issuer interface {
-	Get(ctx context.Context, client client.Client, key client.ObjectKey) error
+	Get(ctx context.Context, client client.Reader, key client.ObjectKey) error
 	Kind() string
+	Object() client.Object
 	Spec() certmanagerv1beta1.DigicertIssuerSpec
 	Status() *certmanagerv1beta1.DigicertIssuerStatus
 	SetStatus(*certmanagerv1beta1.DigicertIssuerStatus)
@@ -44,7 +45,7 @@ type DigicertIssuer struct {
 
 var _ Issuer = &DigicertIssuer{}
 
-func (iss *DigicertIssuer) Get(ctx context.Context, client client.Client, key client.ObjectKey) error {
+func (iss *DigicertIssuer) Get(ctx context.Context, client client.Reader, key client.ObjectKey) error {
 	return client.Get(ctx, key, &iss.DigicertIssuer)
 }
 
@@ -52,6 +53,10 @@ func (iss *DigicertIssuer) Kind() string {
 	return "DigicertIssuer"
 }
 
+func (iss *DigicertIssuer) Object() client.Object {
+	return &iss.DigicertIssuer
+}
+
 func (iss *DigicertIssuer) Status() *certmanagerv1beta1.DigicertIssuerStatus {
 	return iss.DigicertIssuer.Status
 }
@@ -86,7 +91,7 @@ type ClusterDigicertIssuer struct {
 
 var _ Issuer = &ClusterDigicertIssuer{}
 
-func (iss *ClusterDigicertIssuer) Get(ctx context.Context, client client.Client, key client.ObjectKey) error {
+func (iss *ClusterDigicertIssuer) Get(ctx context.Context, client client.Reader, key client.ObjectKey) error {
 	return client.Get(ctx, key, &iss.ClusterDigicertIssuer)
 }
 
@@ -98,6 +103,10 @@ func (iss *ClusterDigicertIssuer) Kind() string {
 	return "ClusterDigicertIssuer"
 }
 
+func (iss *ClusterDigicertIssuer) Object() client.Object {
+	return &iss.ClusterDigicertIssuer
+}
+
 func (iss *ClusterDigicertIssuer) Spec() certmanagerv1beta1.DigicertIssuerSpec {
 	return iss.ClusterDigicertIssuer.Spec
 }
@@ -148,11 +157,16 @@ func SetDigicertIssuerStatusConditionType(ctx context.Context, k8sClient client.
 		return cur.PatchStatus(ctx, k8sClient, newStatus)
 	}
 
+	found := false
 	for idx, curCondition := range newStatus.Conditions {
 		if curCondition.Type == newCondition.Type {
 			newStatus.Conditions[idx] = newCondition
+			found = true
 		}
 	}
+	if !found {
+		newStatus.Conditions = append(newStatus.Conditions, newCondition)
+	}
 
 	return cur.PatchStatus(ctx, k8sClient, newStatus)
 }
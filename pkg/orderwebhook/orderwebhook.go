@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package orderwebhook receives the order status callbacks of CertCentral.
+package orderwebhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// SignatureHeader carries the hex encoded HMAC-SHA256 of the timestamp, a dot and the body keyed with the shared secret,
+	// optionally prefixed with "sha256=".
+	SignatureHeader = "X-Signature"
+	// TimestampHeader carries the Unix time the callback was signed at.
+	TimestampHeader = "X-Timestamp"
+	// SecretQueryParameter carries the shared secret for callbacks whose URL is the only configurable part.
+	SecretQueryParameter = "secret"
+
+	// maxSignatureAge is the maximum difference between the timestamp of a signed callback and the current time.
+	// Older signatures are rejected, so captured callbacks can't be replayed later.
+	maxSignatureAge = 5 * time.Minute
+
+	maxBodySize = 1 << 20
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricCallbacks)
+}
+
+var metricCallbacks = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "digicertissuer_order_callbacks_total",
+		Help: "Number of order status callbacks received from CertCentral",
+	},
+	[]string{
+		"result",
+	},
+)
+
+// Event is an order status callback of CertCentral.
+type Event struct {
+	Event string `json:"event"`
+	Data  struct {
+		OrderID       int    `json:"order_id"`
+		CertificateID int    `json:"certificate_id,omitempty"`
+		Status        string `json:"status,omitempty"`
+	} `json:"data"`
+}
+
+// NotifyFunc is called for every authenticated callback of an order.
+type NotifyFunc func(ctx context.Context, event Event) error
+
+// Handler authenticates the callbacks and passes the events of orders to the NotifyFunc.
+type Handler struct {
+	secret []byte
+	notify NotifyFunc
+	log    logr.Logger
+	now    func() time.Time
+}
+
+var _ http.Handler = &Handler{}
+
+// NewHandler returns a handler accepting callbacks signed with or carrying the shared secret.
+func NewHandler(secret string, notify NotifyFunc, log logr.Logger) (*Handler, error) {
+	if secret == "" {
+		return nil, errors.New("the shared secret of the order webhook must not be empty")
+	}
+	return &Handler{secret: []byte(secret), notify: notify, log: log, now: time.Now}, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		h.respond(w, http.StatusMethodNotAllowed, "invalid")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		h.respond(w, http.StatusBadRequest, "invalid")
+		return
+	}
+	if !h.authenticate(r, body) {
+		h.respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		h.respond(w, http.StatusBadRequest, "invalid")
+		return
+	}
+	// Callbacks without order, e.g. a test of the webhook, are acknowledged.
+	if event.Data.OrderID == 0 {
+		h.respond(w, http.StatusOK, "ignored")
+		return
+	}
+
+	h.log.V(4).Info("received order callback", "event", event.Event, "orderID", event.Data.OrderID, "status", event.Data.Status)
+	if err := h.notify(r.Context(), event); err != nil {
+		h.log.Error(err, "failed to handle order callback", "orderID", event.Data.OrderID)
+		h.respond(w, http.StatusInternalServerError, "error")
+		return
+	}
+	h.respond(w, http.StatusOK, "ok")
+}
+
+// authenticate verifies the signature of the timestamp and body if given, and the shared secret otherwise.
+func (h *Handler) authenticate(r *http.Request, body []byte) bool {
+	if signature := r.Header.Get(SignatureHeader); signature != "" {
+		return h.verifySignature(signature, r.Header.Get(TimestampHeader), body)
+	}
+
+	secret := r.URL.Query().Get(SecretQueryParameter)
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		secret = bearer
+	}
+	return secret != "" && subtle.ConstantTimeCompare([]byte(secret), h.secret) == 1
+}
+
+// verifySignature verifies the signature of the timestamp and body and rejects timestamps not within maxSignatureAge.
+func (h *Handler) verifySignature(signature, timestamp string, body []byte) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := h.now().Sub(time.Unix(unix, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return false
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hmac.Equal(decoded, mac.Sum(nil))
+}
+
+// respond writes the status code and counts the callback by result.
+func (h *Handler) respond(w http.ResponseWriter, code int, result string) {
+	metricCallbacks.WithLabelValues(result).Inc()
+	w.WriteHeader(code)
+}
@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package orderwebhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	testSecret  = "s3cr3t"
+	testPayload = `{"event": "certificate_issued", "data": {"order_id": 42, "certificate_id": 43, "status": "issued"}}`
+)
+
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func sign(body, secret string, timestamp time.Time) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10) + "."))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signed returns the headers of a callback signed at the timestamp.
+func signed(body, secret string, timestamp time.Time) map[string]string {
+	return map[string]string{
+		SignatureHeader: sign(body, secret, timestamp),
+		TimestampHeader: strconv.FormatInt(timestamp.Unix(), 10),
+	}
+}
+
+// signBody returns the signature of the body only, as accepted before timestamps were required.
+func signBody(body, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		header       map[string]string
+		body         string
+		notifyErr    error
+		expectedCode int
+		expectNotify bool
+	}{
+		{
+			name:         "signature",
+			header:       signed(testPayload, testSecret, testNow.Add(-time.Minute)),
+			expectedCode: http.StatusOK,
+			expectNotify: true,
+		},
+		{
+			name:         "invalid signature",
+			header:       signed(testPayload, "other", testNow),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "signature of other body",
+			header:       signed(`{}`, testSecret, testNow),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "stale signature",
+			header:       signed(testPayload, testSecret, testNow.Add(-maxSignatureAge-time.Second)),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "signature from the future",
+			header:       signed(testPayload, testSecret, testNow.Add(maxSignatureAge+time.Second)),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name: "signature with other timestamp",
+			header: map[string]string{
+				SignatureHeader: sign(testPayload, testSecret, testNow.Add(-time.Hour)),
+				TimestampHeader: strconv.FormatInt(testNow.Unix(), 10),
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "signature without timestamp",
+			header:       map[string]string{SignatureHeader: signBody(testPayload, testSecret)},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "bearer secret",
+			header:       map[string]string{"Authorization": "Bearer " + testSecret},
+			expectedCode: http.StatusOK,
+			expectNotify: true,
+		},
+		{
+			name:         "invalid bearer secret",
+			header:       map[string]string{"Authorization": "Bearer other"},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "query secret",
+			target:       "/?" + SecretQueryParameter + "=" + testSecret,
+			expectedCode: http.StatusOK,
+			expectNotify: true,
+		},
+		{
+			name:         "invalid query secret",
+			target:       "/?" + SecretQueryParameter + "=other",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid signature with valid query secret",
+			target:       "/?" + SecretQueryParameter + "=" + testSecret,
+			header:       signed(testPayload, "other", testNow),
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "unauthenticated",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "method not allowed",
+			method:       http.MethodGet,
+			header:       signed(testPayload, testSecret, testNow),
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:         "invalid payload",
+			header:       signed(`{"event":`, testSecret, testNow),
+			body:         `{"event":`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "without order",
+			header:       signed(`{"event": "webhook_test"}`, testSecret, testNow),
+			body:         `{"event": "webhook_test"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "notify error",
+			header:       signed(testPayload, testSecret, testNow),
+			notifyErr:    errors.New("boom"),
+			expectedCode: http.StatusInternalServerError,
+			expectNotify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notified []Event
+			handler, err := NewHandler(testSecret, func(_ context.Context, event Event) error {
+				notified = append(notified, event)
+				return tt.notifyErr
+			}, logr.Discard())
+			if err != nil {
+				t.Fatalf("NewHandler returned error: %v", err)
+			}
+			handler.now = func() time.Time { return testNow }
+
+			method, target, body := tt.method, tt.target, tt.body
+			if method == "" {
+				method = http.MethodPost
+			}
+			if target == "" {
+				target = "/"
+			}
+			if body == "" {
+				body = testPayload
+			}
+			req := httptest.NewRequest(method, target, strings.NewReader(body))
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedCode {
+				t.Fatalf("expected status %d, got %d", tt.expectedCode, rec.Code)
+			}
+			if !tt.expectNotify {
+				if len(notified) != 0 {
+					t.Fatalf("expected no notification, got %v", notified)
+				}
+				return
+			}
+			if len(notified) != 1 {
+				t.Fatalf("expected 1 notification, got %v", notified)
+			}
+			if event := notified[0]; event.Event != "certificate_issued" || event.Data.OrderID != 42 || event.Data.Status != "issued" {
+				t.Fatalf("unexpected event %+v", event)
+			}
+		})
+	}
+}
+
+func TestNewHandlerWithoutSecret(t *testing.T) {
+	if _, err := NewHandler("", nil, logr.Discard()); err == nil {
+		t.Fatal("expected error for empty secret")
+	}
+}
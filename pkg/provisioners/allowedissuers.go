@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+// UnexpectedIssuerError is returned if a certificate chain contains a CA certificate
+// not listed in the allowedIssuerFingerprints of the provisioner.
+type UnexpectedIssuerError struct {
+	CommonName  string
+	Fingerprint string
+}
+
+func (e *UnexpectedIssuerError) Error() string {
+	return fmt.Sprintf("CA certificate %q with SHA-256 fingerprint %s is not in spec.provisioner.allowedIssuerFingerprints", e.CommonName, e.Fingerprint)
+}
+
+// newAllowedIssuers returns the set of allowed CA certificate fingerprints or nil if all are allowed.
+func newAllowedIssuers(provisionerSpec v1beta1.DigicertProvisioner) map[string]bool {
+	if len(provisionerSpec.AllowedIssuerFingerprints) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(provisionerSpec.AllowedIssuerFingerprints))
+	for _, fp := range provisionerSpec.AllowedIssuerFingerprints {
+		allowed[normalizeFingerprint(fp)] = true
+	}
+	return allowed
+}
+
+// checkAllowedIssuers returns an UnexpectedIssuerError for the first CA certificate of the chain that is not allowed.
+func (c *CertCentral) checkAllowedIssuers(crtChain []*x509.Certificate) error {
+	if c.allowedIssuers == nil {
+		return nil
+	}
+
+	for _, crt := range crtChain {
+		if !crt.IsCA {
+			continue
+		}
+		if fp := fingerprint(crt.Raw); !c.allowedIssuers[fp] {
+			return &UnexpectedIssuerError{CommonName: crt.Subject.CommonName, Fingerprint: fp}
+		}
+	}
+	return nil
+}
+
+func validateAllowedIssuerFingerprints(provisionerSpec v1beta1.DigicertProvisioner) error {
+	var errs error
+	for idx, fp := range provisionerSpec.AllowedIssuerFingerprints {
+		if b, err := hex.DecodeString(normalizeFingerprint(fp)); err != nil || len(b) != sha256.Size {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.allowedIssuerFingerprints[%d] is not a hex encoded SHA-256 fingerprint", idx))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+func TestCertCentralAllowedIssuers(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+
+	intermediateFP := fingerprint(fixture.intermediate.Raw)
+	rootFP := fingerprint(fixture.root.Raw)
+
+	tests := []struct {
+		name               string
+		allowed            []string
+		expectedUnexpected string
+	}{
+		{
+			name: "all allowed if empty",
+		},
+		{
+			name:    "intermediate and root allowed",
+			allowed: []string{intermediateFP, strings.ToUpper(rootFP)},
+		},
+		{
+			name:               "root not allowed",
+			allowed:            []string{intermediateFP},
+			expectedUnexpected: "Verify Root",
+		},
+		{
+			name:               "intermediate not allowed",
+			allowed:            []string{rootFP, fingerprint(fixture.unrelatedRoot.Raw)},
+			expectedUnexpected: "Verify Intermediate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provisioner := &CertCentral{
+				allowedIssuers: newAllowedIssuers(v1beta1.DigicertProvisioner{AllowedIssuerFingerprints: tt.allowed}),
+			}
+
+			_, _, err := provisioner.encodeChain([]*x509.Certificate{leaf, fixture.intermediate, fixture.root})
+			if tt.expectedUnexpected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var unexpectedIssuer *UnexpectedIssuerError
+			if !errors.As(err, &unexpectedIssuer) {
+				t.Fatalf("expected UnexpectedIssuerError, got %v", err)
+			}
+			if unexpectedIssuer.CommonName != tt.expectedUnexpected {
+				t.Fatalf("expected %q to be rejected, got %q", tt.expectedUnexpected, unexpectedIssuer.CommonName)
+			}
+		})
+	}
+}
+
+func TestValidateAllowedIssuerFingerprints(t *testing.T) {
+	valid := strings.Repeat("ab:", 31) + "ab"
+	if err := validateAllowedIssuerFingerprints(v1beta1.DigicertProvisioner{AllowedIssuerFingerprints: []string{valid}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := validateAllowedIssuerFingerprints(v1beta1.DigicertProvisioner{AllowedIssuerFingerprints: []string{valid, "abcd", "zz"}})
+	if err == nil || !strings.Contains(err.Error(), "allowedIssuerFingerprints[1]") || !strings.Contains(err.Error(), "allowedIssuerFingerprints[2]") {
+		t.Fatalf("expected invalid fingerprints to be rejected, got %v", err)
+	}
+}
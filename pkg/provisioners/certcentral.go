@@ -25,25 +25,34 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
 	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
 	certcentral "github.com/sapcc/go-certcentral"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/client-go/tools/record"
 )
 
 const defaultValidityYears = 1
 
 type certCentralClient interface {
-	SubmitOrder(order certcentral.Order, orderType certcentral.OrderType) (*certcentral.Order, error)
-	GetCertificateChain(certID string) ([]certcentral.CertificateChain, error)
+	SubmitOrder(ctx context.Context, order orderRequest, orderType certcentral.OrderType) (*Order, error)
+	GetCertificateChain(ctx context.Context, certID string) ([]certcentral.CertificateChain, error)
+	DownloadCertificate(ctx context.Context, certificateID string, certFormat certcentral.CertificateFormat) ([]*x509.Certificate, error)
+	ListCustomFields(ctx context.Context) ([]customFieldDefinition, error)
+	ListOrderStatusChanges(ctx context.Context, from, to time.Time) ([]OrderStatusChange, error)
 }
 
 type CertCentral struct {
 	name     string
 	log      logr.Logger
 	client   certCentralClient
+	account  string
 	recorder record.EventRecorder
 
 	validityDays        *int
@@ -53,20 +62,65 @@ type CertCentral struct {
 	organizationalUnits []string
 	skipApproval,
 	disableRenewalNotifications bool
-	orderType      certcentral.OrderType
-	paymentMethod  certcentral.PaymentMethod
-	containerID    int
-	preferredChain string
+	orderType       certcentral.OrderType
+	paymentMethod   certcentral.PaymentMethod
+	containerID     int
+	preferredChain  string
+	preferredChains []chainPreference
+	allowedIssuers  map[string]bool
+	requesterPolicy *v1beta1.RequesterPolicy
+	quota           *quota
+	suspend         bool
+	issuanceWindows []issuanceWindow
+	polling         pollingPolicy
+
+	containerLabel   string
+	containerMapping map[string]int
+	orderTemplates   *orderTemplates
+	additionalEmails []string
+
+	signatureHash  certcentral.SignatureHash
+	serverPlatform certcentral.ServerPlatformType
+
+	caCertIDsByKey []caCertIDByKey
+
+	// aiaClient fetches missing issuers via Authority Information Access.
+	aiaClient *http.Client
+
+	// chainCache caches the intermediates, so only the leaf needs to be downloaded.
+	chainCache *ChainCache
+
+	includeRootInCA    *bool
+	appendRootToChain  bool
+	excludeCrossSigned bool
+}
+
+type caCertIDByKey struct {
+	keyAlgorithm x509.PublicKeyAlgorithm
+	keySize      int
+	caCertID     string
+}
+
+// OrderOptions contains request-scoped settings for an order that are not part of the CertificateRequest.
+type OrderOptions struct {
+	// ContainerID overrides the division of the issuer if set.
+	ContainerID int
+
+	// ClusterName is the name of the cluster available to order templates.
+	ClusterName string
+
+	// OnSubmitted is called as soon as the order was placed, before the certificate chain is processed.
+	OnSubmitted func(order *Order)
 }
 
 func (c CertCentral) GetName() string {
 	return c.name
 }
 
-func New(name string, issuerSpec v1beta1.DigicertIssuerSpec, apiToken string, log logr.Logger, recorder record.EventRecorder) (*CertCentral, error) {
-	client, err := certcentral.New(&certcentral.Options{
-		Token: apiToken,
-	})
+// New returns the provisioner for the issuer. The chain cache should be shared by all provisioners.
+// Chains are not cached if it is nil.
+func New(name string, issuerSpec v1beta1.DigicertIssuerSpec, apiToken string, chainCache *ChainCache, log logr.Logger, recorder record.EventRecorder) (*CertCentral, error) {
+	client, err := newAPIClient(apiToken)
 	if err != nil {
 		return nil, err
 	}
@@ -120,15 +174,77 @@ func New(name string, issuerSpec v1beta1.DigicertIssuerSpec, apiToken string, lo
 		paymentMethod = m
 	}
 
+	signatureHash := certcentral.SignatureHashes.SHA256
+	if strings.EqualFold(issuerSpec.Provisioner.SignatureHash, signatureHashAuto) {
+		signatureHash = signatureHashAuto
+	} else if h, ok := mapToSignatureHash(issuerSpec.Provisioner.SignatureHash); ok {
+		signatureHash = h
+	}
+
+	serverPlatform := certcentral.ServerPlatformTypes.Nginx
+	if p, ok := mapToServerPlatformType(issuerSpec.Provisioner.ServerPlatform); ok {
+		serverPlatform = p
+	}
+
+	caCertIDsByKey := make([]caCertIDByKey, 0, len(issuerSpec.Provisioner.CACertIDsByKey))
+	for _, sel := range issuerSpec.Provisioner.CACertIDsByKey {
+		keyAlgorithm, ok := mapToPublicKeyAlgorithm(sel.KeyAlgorithm)
+		if !ok {
+			return nil, fmt.Errorf("unsupported key algorithm %q", sel.KeyAlgorithm)
+		}
+		var keySize int
+		if sel.KeySize != nil {
+			keySize = *sel.KeySize
+		}
+		caCertIDsByKey = append(caCertIDsByKey, caCertIDByKey{keyAlgorithm: keyAlgorithm, keySize: keySize, caCertID: sel.CACertID})
+	}
+
 	var containerID int
 	if issuerSpec.Provisioner.ContainerID != nil {
 		containerID = *issuerSpec.Provisioner.ContainerID
 	}
 
+	var (
+		containerLabel   string
+		containerMapping = make(map[string]int)
+	)
+	if sel := issuerSpec.Provisioner.ContainerSelector; sel != nil {
+		containerLabel = sel.NamespaceLabel
+		for value, id := range sel.Containers {
+			containerMapping[value] = id
+		}
+	}
+
+	orderTemplates, err := newOrderTemplates(issuerSpec.Provisioner)
+	if err != nil {
+		return nil, err
+	}
+
+	issuanceQuota, err := newQuota(issuerSpec.Quota)
+	if err != nil {
+		return nil, err
+	}
+
+	issuanceWindows, err := newIssuanceWindows(issuerSpec.IssuanceWindows)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		includeRootInCA    *bool
+		appendRootToChain  bool
+		excludeCrossSigned bool
+	)
+	if policy := issuerSpec.ChainPolicy; policy != nil {
+		includeRootInCA = policy.IncludeRootInCA
+		appendRootToChain = policy.AppendRootToChain != nil && *policy.AppendRootToChain
+		excludeCrossSigned = policy.IncludeCrossSigned != nil && !*policy.IncludeCrossSigned
+	}
+
 	return &CertCentral{
 		name:                        name,
 		log:                         log,
-		client:                      client,
+		client:                      &instrumentedClient{certCentralClient: client, issuer: name},
 		recorder:                    recorder,
 		validityYears:               validityYears,
 		validityDays:                validityDays,
@@ -141,10 +257,107 @@ func New(name string, issuerSpec v1beta1.DigicertIssuerSpec, apiToken string, lo
 		paymentMethod:               paymentMethod,
 		containerID:                 containerID,
 		preferredChain:              issuerSpec.Provisioner.PreferredChain,
+		preferredChains:             newChainPreferences(issuerSpec.Provisioner),
+		allowedIssuers:              newAllowedIssuers(issuerSpec.Provisioner),
+		requesterPolicy:             issuerSpec.RequesterPolicy,
+		quota:                       issuanceQuota,
+		suspend:                     issuerSpec.Suspend,
+		issuanceWindows:             issuanceWindows,
+		account:                     accountID(apiToken),
+		polling:                     newPollingPolicy(issuerSpec.Polling),
+		containerLabel:              containerLabel,
+		containerMapping:            containerMapping,
+		orderTemplates:              orderTemplates,
+		additionalEmails:            issuerSpec.Provisioner.AdditionalEmails,
+		signatureHash:               signatureHash,
+		serverPlatform:              serverPlatform,
+		caCertIDsByKey:              caCertIDsByKey,
+		aiaClient:                   aiaHTTPClient,
+		chainCache:                  chainCache,
+		includeRootInCA:             includeRootInCA,
+		appendRootToChain:           appendRootToChain,
+		excludeCrossSigned:          excludeCrossSigned,
 	}, nil
 }
 
-func (c *CertCentral) Sign(ctx context.Context, cr *certmanagerv1.CertificateRequest) ([]byte, []byte, *certcentral.Order, error) {
+// ContainerLabel returns the namespace label used to select the division or an empty string if not configured.
+func (c *CertCentral) ContainerLabel() string {
+	return c.containerLabel
+}
+
+// OrderType returns the type of the certificates ordered by the provisioner.
+func (c *CertCentral) OrderType() string {
+	return c.orderType.String()
+}
+
+// ContainerID returns the division for an order from a namespace with the given labels.
+// Falls back to the configured containerID if the namespace label has no mapping.
+func (c *CertCentral) ContainerID(namespaceLabels map[string]string) int {
+	if c.containerLabel == "" {
+		return c.containerID
+	}
+	value, ok := namespaceLabels[c.containerLabel]
+	if !ok {
+		return c.containerID
+	}
+	if id, ok := c.containerMapping[value]; ok {
+		return id
+	}
+	return c.containerID
+}
+
+func (c *CertCentral) getSignatureHash(certReq *x509.CertificateRequest) certcentral.SignatureHash {
+	switch c.signatureHash {
+	case "":
+		return certcentral.SignatureHashes.SHA256
+	case signatureHashAuto:
+		return signatureHashForKey(certReq)
+	default:
+		return c.signatureHash
+	}
+}
+
+// getCACertID returns the ID of the CA for the public key of the certificate request.
+// The CA ID is only used if no CA is selected by key, so keys without a mapping don't end up with the CA of another key type.
+func (c *CertCentral) getCACertID(certReq *x509.CertificateRequest) (string, error) {
+	if len(c.caCertIDsByKey) == 0 {
+		return c.caCertID, nil
+	}
+
+	keySize := publicKeySize(certReq)
+	for _, sel := range c.caCertIDsByKey {
+		if sel.keyAlgorithm == certReq.PublicKeyAlgorithm && (sel.keySize == 0 || sel.keySize == keySize) {
+			return sel.caCertID, nil
+		}
+	}
+
+	return "", fmt.Errorf("no CA configured for %s key of size %d", certReq.PublicKeyAlgorithm, keySize)
+}
+
+// chainCacheHint returns the hint the issuer chain of the certificate request is cached under.
+// DigiCert issues from the same intermediate for a CA or, if no CA is configured, for a product and key algorithm.
+// Requests without a CA for their key are not cached under the hint of another CA.
+func (c *CertCentral) chainCacheHint(cr *certmanagerv1.CertificateRequest) string {
+	certReq, err := decodeCertificateRequest(cr.Spec.Request)
+	if err != nil {
+		return ""
+	}
+	if caCertID, err := c.getCACertID(certReq); err == nil && caCertID != "" {
+		return "ca:" + caCertID
+	}
+	return fmt.Sprintf("%s:%s", c.OrderType(), certReq.PublicKeyAlgorithm)
+}
+
+func (c *CertCentral) getServerPlatform() certcentral.ServerPlatformType {
+	if c.serverPlatform == 0 {
+		return certcentral.ServerPlatformTypes.Nginx
+	}
+	return c.serverPlatform
+}
+
+// Sign places an order for the CertificateRequest and returns the CA and certificate chain if issued immediately.
+// Errors occurring after the order was placed are returned together with the order.
+func (c *CertCentral) Sign(ctx context.Context, cr *certmanagerv1.CertificateRequest, opts OrderOptions) ([]byte, []byte, *Order, error) {
 	certReq, err := decodeCertificateRequest(cr.Spec.Request)
 	if err != nil {
 		return nil, nil, nil, err
@@ -154,6 +367,11 @@ func (c *CertCentral) Sign(ctx context.Context, cr *certmanagerv1.CertificateReq
 		return nil, nil, nil, err
 	}
 
+	caCertID, err := c.getCACertID(certReq)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
 	sans := certReq.DNSNames
 	for _, ipAddr := range certReq.IPAddresses {
 		sans = append(sans, ipAddr.String())
@@ -167,14 +385,24 @@ func (c *CertCentral) Sign(ctx context.Context, cr *certmanagerv1.CertificateReq
 		orderValidity.Years = *c.validityYears
 	}
 
-	orderResponse, err := c.client.SubmitOrder(certcentral.Order{
+	containerID := c.containerID
+	if opts.ContainerID > 0 {
+		containerID = opts.ContainerID
+	}
+
+	comments, customFields, err := c.orderTemplates.render(newOrderMetadata(cr, opts.ClusterName))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	orderResponse, err := c.client.SubmitOrder(ctx, orderRequest{Order: certcentral.Order{
 		Certificate: certcentral.Certificate{
 			CommonName:        getCommonName(certReq),
 			DNSNames:          sans,
 			CSR:               string(cr.Spec.Request),
-			ServerPlatform:    certcentral.ServerPlatformForType(certcentral.ServerPlatformTypes.Nginx),
-			SignatureHash:     certcentral.SignatureHashes.SHA256,
-			CaCertID:          c.caCertID,
+			ServerPlatform:    certcentral.ServerPlatformForType(c.getServerPlatform()),
+			SignatureHash:     c.getSignatureHash(certReq),
+			CaCertID:          caCertID,
 			OrganizationUnits: c.organizationalUnits,
 		},
 		OrderValidity:               orderValidity,
@@ -185,30 +413,41 @@ func (c *CertCentral) Sign(ctx context.Context, cr *certmanagerv1.CertificateReq
 			ID: c.organizationID,
 		},
 		Container: &certcentral.Container{
-			ID: c.containerID,
+			ID: containerID,
 		},
-	}, c.orderType)
+		Comments: comments,
+	}, CustomFields: customFields, AdditionalEmails: c.additionalEmails}, c.orderType)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	if opts.OnSubmitted != nil {
+		opts.OnSubmitted(orderResponse)
+	}
 
-	// TODO: This currently relies on skipApproval=true, so the certificates are returned immediately.
-	// If that's not the case, the certificateIDs needs to be extracted from the response and
-	// each element of the certificate chain needs to be downloaded.
-	// Bonus points: Cache the CA and intermediate as they won't change and only download the missing certificate.
 	crtChain, err := orderResponse.DecodeCertificateChain()
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, orderResponse, err
+	}
+	// The chain is only returned if the order was issued immediately, e.g. with skipApproval.
+	// Otherwise, the order is pending and the certificate is downloaded once issued.
+	if len(crtChain) == 0 {
+		return nil, nil, orderResponse, nil
 	}
 
-	crtChain, err = c.buildPreferredChain(crtChain, c.preferredChain, cr)
+	crtChain, err = c.normalizeChain(ctx, crtChain, cr)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, orderResponse, err
 	}
+	c.chainCache.add(c.chainCacheHint(cr), crtChain)
 
-	rootCAPEM, crtChainPEMs, err := encodePem(crtChain)
+	crtChain, err = c.buildPreferredChain(ctx, crtChain, cr)
 	if err != nil {
-		return nil, nil, nil, err
+		return nil, nil, orderResponse, err
+	}
+
+	rootCAPEM, crtChainPEMs, err := c.encodeChain(crtChain)
+	if err != nil {
+		return nil, nil, orderResponse, err
 	}
 
 	return rootCAPEM, crtChainPEMs, orderResponse, nil
@@ -221,7 +460,27 @@ func (c *CertCentral) Download(ctx context.Context, cr *certmanagerv1.Certificat
 		return nil, nil, fmt.Errorf("no cert id given for %s", cr.ObjectMeta.Name)
 	}
 
-	chain, err := c.client.GetCertificateChain(certID)
+	// Only the leaf needs to be downloaded if its issuer chain is likely cached. Otherwise, the chain is fetched at once.
+	hint := c.chainCacheHint(cr)
+	if c.chainCache.has(hint) {
+		crts, err := c.client.DownloadCertificate(ctx, certID, certcentral.CertificateFormats.PEMNoIntermediate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error receiving certificate %s for request %s: %s", certID, cr.ObjectMeta.Name, err)
+		}
+		leaf := findLeaf(crts)
+		if leaf == nil {
+			return nil, nil, fmt.Errorf("leaf certificate %s for request %s not found", certID, cr.ObjectMeta.Name)
+		}
+		if crtChain, ok := c.chainCache.get(leaf); ok {
+			crtChain, err = c.buildPreferredChain(ctx, crtChain, cr)
+			if err != nil {
+				return nil, nil, err
+			}
+			return c.encodeChain(crtChain)
+		}
+	}
+
+	chain, err := c.client.GetCertificateChain(ctx, certID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error receiving certificate chain %s for request %s: %s", certID, cr.ObjectMeta.Name, err)
 	}
@@ -235,19 +494,34 @@ func (c *CertCentral) Download(ctx context.Context, cr *certmanagerv1.Certificat
 		crtChain = append(crtChain, decodedCrt...)
 	}
 
-	crtChain, err = c.buildPreferredChain(crtChain, c.preferredChain, cr)
+	crtChain, err = c.normalizeChain(ctx, crtChain, cr)
 	if err != nil {
 		return nil, nil, err
 	}
+	c.chainCache.add(hint, crtChain)
 
-	return encodePem(crtChain)
+	crtChain, err = c.buildPreferredChain(ctx, crtChain, cr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c.encodeChain(crtChain)
 }
 
-func (c *CertCentral) buildPreferredChain(certBundle []*x509.Certificate, preferredRootCN string, cr *certmanagerv1.CertificateRequest) ([]*x509.Certificate, error) {
+// buildPreferredChain selects the chain anchored at the root matching the first preference for which a chain verifies.
+// The selection is recorded in the AnnotationKeyPreferredChain annotation of the CertificateRequest.
+func (c *CertCentral) buildPreferredChain(ctx context.Context, certBundle []*x509.Certificate, cr *certmanagerv1.CertificateRequest) ([]*x509.Certificate, error) {
+	_, span := tracing.Start(ctx, "CertCentral.buildPreferredChain", tracing.AttributeCertificateRequestUID.String(string(cr.UID)))
+	defer func() {
+		span.SetAttributes(attribute.String("digicert.preferred_chain", cr.GetAnnotations()[AnnotationKeyPreferredChain]))
+		span.End()
+	}()
+
 	if len(certBundle) == 0 {
 		return nil, errors.New("empty certificate bundle")
 	}
-	if preferredRootCN == "" {
+	preferences := c.getChainPreferences()
+	if len(preferences) == 0 {
 		c.log.Info("preferred-chain: no preferredRoot configured, using default chain", "namespace", cr.Namespace, "name", cr.Name)
 		return certBundle, nil
 	}
@@ -278,25 +552,78 @@ func (c *CertCentral) buildPreferredChain(certBundle []*x509.Certificate, prefer
 	})
 	if err != nil {
 		c.log.Info("preferred-chain: unable to build preferred chain, using default chain", "error", err, "namespace", cr.Namespace, "name", cr.Name)
-		c.recorder.Eventf(cr, "Warning", "PreferredChainFallback", "preferred chain %q not found, using default chain", preferredRootCN)
+		c.recorder.Eventf(cr, "Warning", "PreferredChainFallback", "preferred chain %q not found, using default chain", chainPreferencesString(preferences))
+		setAnnotation(cr, AnnotationKeyPreferredChain, preferredChainDefault)
 		return certBundle, nil
 	}
-	for _, chain := range chains {
-		if len(chain) == 0 {
-			continue
-		}
-		root := chain[len(chain)-1]
-		if root.Subject.CommonName == preferredRootCN && isSelfSigned(root) {
-			c.log.Info("preferred-chain: selected preferred root", "preferredRootCN", preferredRootCN, "namespace", cr.Namespace, "name", cr.Name)
-			return chain, nil
+	for idx, pref := range preferences {
+		for _, chain := range chains {
+			if len(chain) == 0 {
+				continue
+			}
+			if pref.matches(chain[len(chain)-1]) {
+				c.log.Info("preferred-chain: selected preferred root", "preference", pref.String(), "namespace", cr.Namespace, "name", cr.Name)
+				setAnnotation(cr, AnnotationKeyPreferredChain, fmt.Sprintf("preferredChains[%d]: %s", idx, pref))
+				return chain, nil
+			}
 		}
 	}
-	c.log.Info("preferred-chain: preferred root not found, using default chain", "preferredRootCN", preferredRootCN, "namespace", cr.Namespace, "name", cr.Name)
-	c.recorder.Eventf(cr, "Warning", "PreferredChainFallback", "preferred chain %q not found, using default chain", preferredRootCN)
+	c.log.Info("preferred-chain: preferred root not found, using default chain", "preferences", chainPreferencesString(preferences), "namespace", cr.Namespace, "name", cr.Name)
+	c.recorder.Eventf(cr, "Warning", "PreferredChainFallback", "preferred chain %q not found, using default chain", chainPreferencesString(preferences))
+	setAnnotation(cr, AnnotationKeyPreferredChain, preferredChainDefault)
 
 	return certBundle, nil
 }
 
+// getChainPreferences returns the preferred chains falling back to the preferred root common name.
+func (c *CertCentral) getChainPreferences() []chainPreference {
+	if len(c.preferredChains) > 0 {
+		return c.preferredChains
+	}
+	if c.preferredChain != "" {
+		return []chainPreference{{rootCommonName: c.preferredChain}}
+	}
+	return nil
+}
+
+func setAnnotation(cr *certmanagerv1.CertificateRequest, key, value string) {
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = value
+	cr.SetAnnotations(annotations)
+}
+
+// IncludeRootInCA returns whether the root certificate is set as CA of the CertificateRequest.
+// Returns the given default if not configured by the chain policy of the issuer.
+func (c *CertCentral) IncludeRootInCA(defaultValue bool) bool {
+	if c.includeRootInCA == nil {
+		return defaultValue
+	}
+	return *c.includeRootInCA
+}
+
+// encodeChain applies the chain policy of the issuer, rejects CA certificates not allowed and returns the PEM encoded
+// roots and certificate chain.
+func (c *CertCentral) encodeChain(crtChain []*x509.Certificate) ([]byte, []byte, error) {
+	if c.excludeCrossSigned {
+		crtChain = pathFromLeaf(crtChain)
+	}
+	if err := c.checkAllowedIssuers(crtChain); err != nil {
+		return nil, nil, err
+	}
+
+	rootCAPEM, crtChainPEMs, err := encodePem(crtChain)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.appendRootToChain {
+		crtChainPEMs = append(crtChainPEMs, rootCAPEM...)
+	}
+	return rootCAPEM, crtChainPEMs, nil
+}
+
 func encodePem(crtChain []*x509.Certificate) ([]byte, []byte, error) {
 	rootCAPEM := make([]byte, 0)
 	crtChainPEMs := make([]byte, 0)
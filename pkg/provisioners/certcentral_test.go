@@ -5,14 +5,18 @@ package provisioners
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"math/big"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,16 +31,55 @@ type mockCertCentralClient struct {
 	submitErr   error
 	chain       []certcentral.CertificateChain
 	chainErr    error
+
+	customFields []customFieldDefinition
+
+	submitted []orderRequest
+
+	chainRequests    int
+	downloadRequests int
+
+	statusChanges []OrderStatusChange
 }
 
-func (f *mockCertCentralClient) SubmitOrder(order certcentral.Order, orderType certcentral.OrderType) (*certcentral.Order, error) {
-	return f.submitOrder, f.submitErr
+func (f *mockCertCentralClient) SubmitOrder(_ context.Context, order orderRequest, orderType certcentral.OrderType) (*Order, error) {
+	f.submitted = append(f.submitted, order)
+	if f.submitOrder == nil {
+		return nil, f.submitErr
+	}
+	return &Order{Order: *f.submitOrder}, f.submitErr
 }
 
-func (f *mockCertCentralClient) GetCertificateChain(certID string) ([]certcentral.CertificateChain, error) {
+func (f *mockCertCentralClient) GetCertificateChain(_ context.Context, certID string) ([]certcentral.CertificateChain, error) {
+	f.chainRequests++
 	return f.chain, f.chainErr
 }
 
+func (f *mockCertCentralClient) DownloadCertificate(_ context.Context, certificateID string, certFormat certcentral.CertificateFormat) ([]*x509.Certificate, error) {
+	f.downloadRequests++
+	if f.chainErr != nil {
+		return nil, f.chainErr
+	}
+	for _, crt := range f.chain {
+		decoded, err := crt.DecodePEM()
+		if err != nil {
+			return nil, err
+		}
+		if leaf := findLeaf(decoded); leaf != nil {
+			return []*x509.Certificate{leaf}, nil
+		}
+	}
+	return nil, errors.New("certificate not found")
+}
+
+func (f *mockCertCentralClient) ListCustomFields(_ context.Context) ([]customFieldDefinition, error) {
+	return f.customFields, nil
+}
+
+func (f *mockCertCentralClient) ListOrderStatusChanges(_ context.Context, from, to time.Time) ([]OrderStatusChange, error) {
+	return f.statusChanges, nil
+}
+
 type chainFixture struct {
 	requestedCN       string
 	preferredRoot     string
@@ -117,7 +160,7 @@ func TestCertCentralSignPreferredChain(t *testing.T) {
 				Spec:       certmanagerv1.CertificateRequestSpec{Request: csrPEM},
 			}
 
-			caPEM, tlsPEM, _, err := provisioner.Sign(context.Background(), cr)
+			caPEM, tlsPEM, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{})
 			if err != nil {
 				t.Fatalf("Sign returned error: %v", err)
 			}
@@ -247,6 +290,7 @@ func buildChainFixture(t *testing.T) chainFixture {
 type certSpec struct {
 	cn   string
 	isCA bool
+	aia  []string
 }
 
 func generateRSAKey(t *testing.T) *rsa.PrivateKey {
@@ -269,6 +313,7 @@ func generateCert(t *testing.T, spec certSpec, parent *x509.Certificate, parentK
 		NotAfter:              now.Add(365 * 24 * time.Hour),
 		BasicConstraintsValid: true,
 		IsCA:                  spec.isCA,
+		IssuingCertificateURL: spec.aia,
 	}
 
 	if spec.isCA {
@@ -431,7 +476,7 @@ func TestBuildPreferredChainFromFixture(t *testing.T) {
 				Spec:       certmanagerv1.CertificateRequestSpec{Request: csrPEM},
 			}
 
-			caPEM, tlsPEM, _, err := provisioner.Sign(context.Background(), cr)
+			caPEM, tlsPEM, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{})
 			if err != nil {
 				t.Fatalf("Sign returned error: %v", err)
 			}
@@ -440,3 +485,318 @@ func TestBuildPreferredChainFromFixture(t *testing.T) {
 		})
 	}
 }
+
+func TestCertCentralContainerSelection(t *testing.T) {
+	fixture := buildChainFixture(t)
+	csrPEM := createCSR(t, fixture.requestedCN)
+
+	provisioner := &CertCentral{
+		containerID:      1,
+		containerLabel:   "cost-center",
+		containerMapping: map[string]int{"team-a": 10, "team-b": 20},
+		recorder:         record.NewFakeRecorder(10),
+	}
+
+	tests := []struct {
+		name                string
+		namespaceLabels     map[string]string
+		expectedContainerID int
+	}{
+		{
+			name:                "mapped_label_value",
+			namespaceLabels:     map[string]string{"cost-center": "team-b"},
+			expectedContainerID: 20,
+		},
+		{
+			name:                "unmapped_label_value",
+			namespaceLabels:     map[string]string{"cost-center": "team-c"},
+			expectedContainerID: 1,
+		},
+		{
+			name:                "missing_label",
+			namespaceLabels:     map[string]string{"other": "team-a"},
+			expectedContainerID: 1,
+		},
+		{
+			name:                "no_labels",
+			namespaceLabels:     nil,
+			expectedContainerID: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containerID := provisioner.ContainerID(tt.namespaceLabels)
+			if containerID != tt.expectedContainerID {
+				t.Fatalf("unexpected container ID, got=%d expected=%d", containerID, tt.expectedContainerID)
+			}
+
+			mock := &mockCertCentralClient{submitOrder: &certcentral.Order{CertificateChain: constructCertificateChain(t, fixture.regularBundle)}}
+			provisioner.client = mock
+
+			cr := &certmanagerv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "container-test"},
+				Spec:       certmanagerv1.CertificateRequestSpec{Request: csrPEM},
+			}
+
+			if _, _, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{ContainerID: containerID}); err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+			if len(mock.submitted) != 1 {
+				t.Fatalf("expected exactly one submitted order, got %d", len(mock.submitted))
+			}
+			if got := mock.submitted[0].Container.ID; got != tt.expectedContainerID {
+				t.Fatalf("unexpected container ID in order, got=%d expected=%d", got, tt.expectedContainerID)
+			}
+		})
+	}
+}
+
+func TestCertCentralSignPendingOrder(t *testing.T) {
+	fixture := buildChainFixture(t)
+	mock := &mockCertCentralClient{submitOrder: &certcentral.Order{ID: 42, CertificateID: 43}}
+	provisioner := &CertCentral{
+		client:   mock,
+		recorder: record.NewFakeRecorder(10),
+	}
+	cr := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending-test"},
+		Spec:       certmanagerv1.CertificateRequestSpec{Request: createCSR(t, fixture.requestedCN)},
+	}
+
+	caPEM, tlsPEM, order, err := provisioner.Sign(context.Background(), cr, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	if len(caPEM) != 0 || len(tlsPEM) != 0 {
+		t.Fatalf("expected no certificate for pending order, got ca=%q tls=%q", caPEM, tlsPEM)
+	}
+	if order == nil || order.ID != 42 || order.CertificateID != 43 {
+		t.Fatalf("expected pending order 42 with certificate 43, got %+v", order)
+	}
+}
+
+func TestCertCentralSignReturnsOrderWithChainError(t *testing.T) {
+	fixture := buildChainFixture(t)
+	mock := &mockCertCentralClient{submitOrder: &certcentral.Order{
+		ID:               42,
+		CertificateID:    43,
+		CertificateChain: constructCertificateChain(t, fixture.regularBundle[1:]),
+	}}
+	provisioner := &CertCentral{
+		client:   mock,
+		recorder: record.NewFakeRecorder(10),
+	}
+	cr := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "chain-error-test"},
+		Spec:       certmanagerv1.CertificateRequestSpec{Request: createCSR(t, fixture.requestedCN)},
+	}
+
+	var submitted *Order
+	_, _, order, err := provisioner.Sign(context.Background(), cr, OrderOptions{
+		OnSubmitted: func(order *Order) { submitted = order },
+	})
+	if err == nil {
+		t.Fatal("expected error for chain without leaf")
+	}
+	if order == nil || order.ID != 42 {
+		t.Fatalf("expected order 42 to be returned with the error, got %+v", order)
+	}
+	if submitted != order {
+		t.Fatalf("expected OnSubmitted to be called with the order, got %+v", submitted)
+	}
+}
+
+func TestCertCentralSignSignatureHash(t *testing.T) {
+	fixture := buildChainFixture(t)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	ecdsaCSR, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: fixture.requestedCN},
+		DNSNames: []string{fixture.requestedCN},
+	}, ecdsaKey)
+	if err != nil {
+		t.Fatalf("create csr: %v", err)
+	}
+	ecdsaCSRPEM := pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificateRequest, Bytes: ecdsaCSR})
+
+	tests := []struct {
+		name                   string
+		csrPEM                 []byte
+		signatureHash          certcentral.SignatureHash
+		serverPlatform         certcentral.ServerPlatformType
+		expectedSignatureHash  certcentral.SignatureHash
+		expectedServerPlatform int
+	}{
+		{
+			name:                   "defaults",
+			csrPEM:                 createCSR(t, fixture.requestedCN),
+			expectedSignatureHash:  certcentral.SignatureHashes.SHA256,
+			expectedServerPlatform: certcentral.ServerPlatformTypes.Nginx.Int(),
+		},
+		{
+			name:                   "explicit",
+			csrPEM:                 createCSR(t, fixture.requestedCN),
+			signatureHash:          certcentral.SignatureHashes.SHA512,
+			serverPlatform:         certcentral.ServerPlatformTypes.Other,
+			expectedSignatureHash:  certcentral.SignatureHashes.SHA512,
+			expectedServerPlatform: certcentral.ServerPlatformTypes.Other.Int(),
+		},
+		{
+			name:                   "auto_rsa",
+			csrPEM:                 createCSR(t, fixture.requestedCN),
+			signatureHash:          signatureHashAuto,
+			expectedSignatureHash:  certcentral.SignatureHashes.SHA256,
+			expectedServerPlatform: certcentral.ServerPlatformTypes.Nginx.Int(),
+		},
+		{
+			name:                   "auto_ecdsa_p384",
+			csrPEM:                 ecdsaCSRPEM,
+			signatureHash:          signatureHashAuto,
+			expectedSignatureHash:  certcentral.SignatureHashes.SHA384,
+			expectedServerPlatform: certcentral.ServerPlatformTypes.Nginx.Int(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCertCentralClient{submitOrder: &certcentral.Order{CertificateChain: constructCertificateChain(t, fixture.regularBundle)}}
+			provisioner := &CertCentral{
+				client:         mock,
+				signatureHash:  tt.signatureHash,
+				serverPlatform: tt.serverPlatform,
+				recorder:       record.NewFakeRecorder(10),
+			}
+
+			cr := &certmanagerv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "signature-hash-test"},
+				Spec:       certmanagerv1.CertificateRequestSpec{Request: tt.csrPEM},
+			}
+
+			if _, _, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{}); err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+
+			crt := mock.submitted[0].Certificate
+			if crt.SignatureHash != tt.expectedSignatureHash {
+				t.Fatalf("unexpected signature hash, got=%s expected=%s", crt.SignatureHash, tt.expectedSignatureHash)
+			}
+			if crt.ServerPlatform.ID != tt.expectedServerPlatform {
+				t.Fatalf("unexpected server platform, got=%d expected=%d", crt.ServerPlatform.ID, tt.expectedServerPlatform)
+			}
+		})
+	}
+}
+
+func TestCertCentralSignCACertIDByKey(t *testing.T) {
+	fixture := buildChainFixture(t)
+
+	createECDSACSR := func(t *testing.T, curve elliptic.Curve) []byte {
+		t.Helper()
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatalf("generate key: %v", err)
+		}
+		csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject:  pkix.Name{CommonName: fixture.requestedCN},
+			DNSNames: []string{fixture.requestedCN},
+		}, key)
+		if err != nil {
+			t.Fatalf("create csr: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificateRequest, Bytes: csrDER})
+	}
+
+	mapping := []caCertIDByKey{
+		{keyAlgorithm: x509.ECDSA, keySize: 384, caCertID: "ECC-P384"},
+		{keyAlgorithm: x509.ECDSA, caCertID: "ECC"},
+		{keyAlgorithm: x509.RSA, keySize: 2048, caCertID: "RSA-2048"},
+	}
+
+	tests := []struct {
+		name             string
+		csrPEM           []byte
+		caCertID         string
+		caCertIDsByKey   []caCertIDByKey
+		expectedCACertID string
+		expectErr        bool
+	}{
+		{
+			name:             "no_mapping",
+			csrPEM:           createCSR(t, fixture.requestedCN),
+			caCertID:         "DEFAULT",
+			expectedCACertID: "DEFAULT",
+		},
+		{
+			name:             "rsa_exact_size",
+			csrPEM:           createCSR(t, fixture.requestedCN),
+			caCertIDsByKey:   mapping,
+			expectedCACertID: "RSA-2048",
+		},
+		{
+			name:             "ecdsa_exact_size",
+			csrPEM:           createECDSACSR(t, elliptic.P384()),
+			caCertIDsByKey:   mapping,
+			expectedCACertID: "ECC-P384",
+		},
+		{
+			name:             "ecdsa_any_size",
+			csrPEM:           createECDSACSR(t, elliptic.P256()),
+			caCertIDsByKey:   mapping,
+			expectedCACertID: "ECC",
+		},
+		{
+			name:           "unmapped_with_default",
+			csrPEM:         createCSR(t, fixture.requestedCN),
+			caCertID:       "DEFAULT",
+			caCertIDsByKey: mapping[:2],
+			expectErr:      true,
+		},
+		{
+			name:           "unmapped_without_default",
+			csrPEM:         createCSR(t, fixture.requestedCN),
+			caCertIDsByKey: mapping[:2],
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockCertCentralClient{submitOrder: &certcentral.Order{CertificateChain: constructCertificateChain(t, fixture.regularBundle)}}
+			provisioner := &CertCentral{
+				client:         mock,
+				caCertID:       tt.caCertID,
+				caCertIDsByKey: tt.caCertIDsByKey,
+				recorder:       record.NewFakeRecorder(10),
+			}
+
+			cr := &certmanagerv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "ca-cert-id-test"},
+				Spec:       certmanagerv1.CertificateRequestSpec{Request: tt.csrPEM},
+			}
+
+			_, _, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if len(mock.submitted) != 0 {
+					t.Fatal("expected no order to be submitted")
+				}
+				if hint := provisioner.chainCacheHint(cr); strings.HasPrefix(hint, "ca:") {
+					t.Fatalf("expected no CA chain cache hint, got %s", hint)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+			if got := mock.submitted[0].Certificate.CaCertID; got != tt.expectedCACertID {
+				t.Fatalf("unexpected CA cert ID, got=%s expected=%s", got, tt.expectedCACertID)
+			}
+		})
+	}
+}
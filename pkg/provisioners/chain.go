@@ -0,0 +1,257 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+const (
+	// maxAIAFetches limits the number of certificates fetched via Authority Information Access for one chain.
+	maxAIAFetches = 5
+
+	// maxAIAResponseSize limits the size of a certificate fetched via Authority Information Access.
+	maxAIAResponseSize = 1 << 20
+
+	aiaFetchTimeout = 10 * time.Second
+)
+
+// aiaHTTPClient is shared by all provisioners to reuse connections to the CA servers.
+var aiaHTTPClient = &http.Client{
+	Timeout: aiaFetchTimeout,
+	Transport: &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: aiaFetchTimeout,
+	},
+}
+
+// normalizeChain orders the certificate bundle starting from the leaf along its issuers, fetches missing issuers
+// from the Authority Information Access URLs and removes duplicates.
+// Certificates not on the path from the leaf to its root, e.g. cross-signed alternatives, are kept after the path.
+// Changes to the bundle are reported as event on the CertificateRequest.
+func (c *CertCentral) normalizeChain(ctx context.Context, certBundle []*x509.Certificate, cr *certmanagerv1.CertificateRequest) ([]*x509.Certificate, error) {
+	bundle, changes := dedupeCertificates(certBundle)
+	received := bundle
+
+	leaf := findLeaf(bundle)
+	if leaf == nil {
+		return nil, errors.New("leaf certificate not found in bundle")
+	}
+
+	path, bundle, fetched := c.completePath(ctx, leaf, bundle, cr.Namespace, cr.Name)
+	changes = append(changes, fetched...)
+
+	normalized := path
+	for _, cert := range bundle {
+		if !containsCertificate(path, cert) {
+			normalized = append(normalized, cert)
+		}
+	}
+
+	if !isSameOrder(received, onlyContained(normalized, received)) {
+		changes = append(changes, "reordered certificates from leaf to root")
+	}
+
+	if len(changes) > 0 {
+		c.log.Info("chain: normalized certificate chain", "changes", changes, "namespace", cr.Namespace, "name", cr.Name)
+		c.recorder.Eventf(cr, "Normal", "ChainNormalized", "certificate chain normalized: %s", strings.Join(changes, "; "))
+	}
+
+	return normalized, nil
+}
+
+// completePath builds the path from the leaf to its root using the bundle and fetching missing issuers via
+// Authority Information Access. Returns the path, the bundle including fetched certificates and a description of
+// the fetched certificates.
+func (c *CertCentral) completePath(ctx context.Context, leaf *x509.Certificate, bundle []*x509.Certificate, namespace, name string) ([]*x509.Certificate, []*x509.Certificate, []string) {
+	path := []*x509.Certificate{leaf}
+	var changes []string
+	fetches := 0
+	for cur := leaf; !isSelfSigned(cur); {
+		issuer := findIssuer(cur, bundle, path)
+		if issuer == nil {
+			if fetches >= maxAIAFetches {
+				break
+			}
+			fetches++
+
+			fetched, url, err := c.fetchIssuer(ctx, cur)
+			if err != nil {
+				c.log.Info("chain: unable to fetch missing issuer", "error", err, "subject", cur.Subject.String(), "namespace", namespace, "name", name)
+				break
+			}
+			if fetched == nil || containsCertificate(path, fetched) {
+				break
+			}
+			bundle = append(bundle, fetched)
+			changes = append(changes, fmt.Sprintf("fetched missing issuer %q from %s", fetched.Subject.CommonName, url))
+			issuer = fetched
+		}
+		path = append(path, issuer)
+		cur = issuer
+	}
+	return path, bundle, changes
+}
+
+// pathFromLeaf returns the path from the leaf to its root removing all other certificates of the chain.
+func pathFromLeaf(chain []*x509.Certificate) []*x509.Certificate {
+	leaf := findLeaf(chain)
+	if leaf == nil {
+		return chain
+	}
+
+	path := []*x509.Certificate{leaf}
+	for cur := leaf; !isSelfSigned(cur); {
+		issuer := findIssuer(cur, chain, path)
+		if issuer == nil {
+			break
+		}
+		path = append(path, issuer)
+		cur = issuer
+	}
+	return path
+}
+
+// findIssuer returns the issuer of the certificate from the bundle ignoring certificates already on the path.
+// Self-signed issuers are preferred over cross-signed ones to keep the default path short.
+func findIssuer(cert *x509.Certificate, bundle, path []*x509.Certificate) *x509.Certificate {
+	var crossSigned *x509.Certificate
+	for _, candidate := range bundle {
+		if containsCertificate(path, candidate) || !bytes.Equal(candidate.RawSubject, cert.RawIssuer) {
+			continue
+		}
+		if cert.CheckSignatureFrom(candidate) != nil {
+			continue
+		}
+		if isSelfSigned(candidate) {
+			return candidate
+		}
+		if crossSigned == nil {
+			crossSigned = candidate
+		}
+	}
+	return crossSigned
+}
+
+// fetchIssuer fetches the issuer of the certificate from its Authority Information Access URLs.
+func (c *CertCentral) fetchIssuer(ctx context.Context, cert *x509.Certificate) (*x509.Certificate, string, error) {
+	if len(cert.IssuingCertificateURL) == 0 {
+		return nil, "", fmt.Errorf("certificate %q has no issuing certificate URL", cert.Subject.CommonName)
+	}
+
+	var errs []string
+	for _, url := range cert.IssuingCertificateURL {
+		issuer, err := c.fetchCertificate(ctx, url)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if err := cert.CheckSignatureFrom(issuer); err != nil {
+			errs = append(errs, fmt.Sprintf("certificate from %s is not the issuer: %v", url, err))
+			continue
+		}
+		return issuer, url, nil
+	}
+	return nil, "", errors.New(strings.Join(errs, "; "))
+}
+
+func (c *CertCentral) fetchCertificate(ctx context.Context, url string) (*x509.Certificate, error) {
+	httpClient := c.aiaClient
+	if httpClient == nil {
+		httpClient = aiaHTTPClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s failed with status %s", url, res.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxAIAResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// Issuing certificates are usually DER encoded, but some CAs serve PEM.
+	if block, _ := pem.Decode(data); block != nil && block.Type == blockTypeCertificate {
+		data = block.Bytes
+	}
+	return x509.ParseCertificate(data)
+}
+
+// dedupeCertificates removes certificates contained multiple times in the bundle.
+// Certificates with the same subject, issuer and public key are considered duplicates even if re-issued.
+func dedupeCertificates(certBundle []*x509.Certificate) ([]*x509.Certificate, []string) {
+	deduped := make([]*x509.Certificate, 0, len(certBundle))
+	var changes []string
+	for _, cert := range certBundle {
+		duplicate := false
+		for _, existing := range deduped {
+			if bytes.Equal(existing.Raw, cert.Raw) || (bytes.Equal(existing.RawSubject, cert.RawSubject) &&
+				bytes.Equal(existing.RawIssuer, cert.RawIssuer) &&
+				bytes.Equal(existing.RawSubjectPublicKeyInfo, cert.RawSubjectPublicKeyInfo)) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			changes = append(changes, fmt.Sprintf("removed duplicate certificate %q", cert.Subject.CommonName))
+			continue
+		}
+		deduped = append(deduped, cert)
+	}
+	return deduped, changes
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if bytes.Equal(c.Raw, cert.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// onlyContained returns the certificates that are also contained in others keeping their order.
+func onlyContained(certs, others []*x509.Certificate) []*x509.Certificate {
+	out := make([]*x509.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		if containsCertificate(others, cert) {
+			out = append(out, cert)
+		}
+	}
+	return out
+}
+
+func isSameOrder(a, b []*x509.Certificate) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if !bytes.Equal(a[idx].Raw, b[idx].Raw) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCertCentralNormalizeChain(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/intermediate.der", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(fixture.intermediate.Raw)
+	})
+	mux.HandleFunc("/intermediate.pem", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificate, Bytes: fixture.intermediate.Raw}))
+	})
+	mux.HandleFunc("/unrelated.der", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(fixture.unrelatedRoot.Raw)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	leafWithAIA := func(path ...string) *x509.Certificate {
+		return issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+			for _, p := range path {
+				crt.IssuingCertificateURL = append(crt.IssuingCertificateURL, server.URL+p)
+			}
+		})
+	}
+	leaf := leafWithAIA()
+	reissuedLeaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+
+	tests := []struct {
+		name           string
+		bundle         []*x509.Certificate
+		expectedCNs    []string
+		expectedEvents []string
+	}{
+		{
+			name:        "already_normalized",
+			bundle:      []*x509.Certificate{leaf, fixture.intermediate, fixture.root},
+			expectedCNs: []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+		},
+		{
+			name:           "reordered",
+			bundle:         []*x509.Certificate{fixture.root, leaf, fixture.intermediate},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+			expectedEvents: []string{"reordered certificates from leaf to root"},
+		},
+		{
+			name:           "duplicate",
+			bundle:         []*x509.Certificate{leaf, fixture.intermediate, fixture.intermediate, fixture.root},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+			expectedEvents: []string{`removed duplicate certificate "Verify Intermediate"`},
+		},
+		{
+			name:        "missing_intermediate_without_aia",
+			bundle:      []*x509.Certificate{leaf, fixture.root},
+			expectedCNs: []string{"verify.test.local", "Verify Root"},
+		},
+		{
+			name:           "missing_intermediate_der",
+			bundle:         []*x509.Certificate{leafWithAIA("/intermediate.der"), fixture.root},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+			expectedEvents: []string{`fetched missing issuer "Verify Intermediate"`},
+		},
+		{
+			name:           "missing_intermediate_pem",
+			bundle:         []*x509.Certificate{leafWithAIA("/intermediate.pem")},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate"},
+			expectedEvents: []string{`fetched missing issuer "Verify Intermediate"`},
+		},
+		{
+			name:           "missing_intermediate_fallback_url",
+			bundle:         []*x509.Certificate{leafWithAIA("/not-found", "/unrelated.der", "/intermediate.der"), fixture.root},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+			expectedEvents: []string{`fetched missing issuer "Verify Intermediate"`},
+		},
+		{
+			name:        "missing_intermediate_not_found",
+			bundle:      []*x509.Certificate{leafWithAIA("/not-found"), fixture.root},
+			expectedCNs: []string{"verify.test.local", "Verify Root"},
+		},
+		{
+			name:           "reissued_duplicate",
+			bundle:         []*x509.Certificate{leaf, fixture.intermediate, reissuedLeaf, fixture.root},
+			expectedCNs:    []string{"verify.test.local", "Verify Intermediate", "Verify Root"},
+			expectedEvents: []string{`removed duplicate certificate "verify.test.local"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			provisioner := &CertCentral{recorder: recorder, aiaClient: server.Client()}
+			cr := &certmanagerv1.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Name: tt.name, Namespace: "default"}}
+
+			chain, err := provisioner.normalizeChain(context.Background(), tt.bundle, cr)
+			if err != nil {
+				t.Fatalf("normalizeChain returned error: %v", err)
+			}
+			if got := certCNs(chain); !reflect.DeepEqual(got, tt.expectedCNs) {
+				t.Fatalf("expected chain %v, got %v", tt.expectedCNs, got)
+			}
+
+			var event string
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if len(tt.expectedEvents) == 0 && event != "" {
+				t.Fatalf("expected no event, got %q", event)
+			}
+			for _, expected := range tt.expectedEvents {
+				if !strings.Contains(event, "ChainNormalized") || !strings.Contains(event, expected) {
+					t.Fatalf("expected event containing %q, got %q", expected, event)
+				}
+			}
+		})
+	}
+}
+
+func TestCertCentralNormalizeChainCrossSigned(t *testing.T) {
+	fixture := buildChainFixture(t)
+	provisioner := &CertCentral{recorder: record.NewFakeRecorder(10)}
+
+	chain, err := provisioner.normalizeChain(context.Background(), fixture.crossSignedBundle, &certmanagerv1.CertificateRequest{})
+	if err != nil {
+		t.Fatalf("normalizeChain returned error: %v", err)
+	}
+
+	// The cross-signed root is not a duplicate and kept after the path to the self-signed root.
+	expected := []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot, fixture.preferredRoot, fixture.globalRoot}
+	if got := certCNs(chain); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected chain %v, got %v", expected, got)
+	}
+	if !isSelfSigned(chain[2]) || isSelfSigned(chain[3]) {
+		t.Fatalf("expected self-signed root on the path followed by the cross-signed root")
+	}
+}
+
+func TestCertCentralEncodeChainPolicy(t *testing.T) {
+	fixture := buildChainFixture(t)
+
+	tests := []struct {
+		name          string
+		provisioner   *CertCentral
+		expectedChain []string
+	}{
+		{
+			name:          "default",
+			provisioner:   &CertCentral{},
+			expectedChain: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+		},
+		{
+			name:          "append root",
+			provisioner:   &CertCentral{appendRootToChain: true},
+			expectedChain: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot, fixture.preferredRoot, fixture.globalRoot},
+		},
+		{
+			name:          "exclude cross-signed",
+			provisioner:   &CertCentral{excludeCrossSigned: true},
+			expectedChain: []string{fixture.requestedCN, "Test Intermediate"},
+		},
+		{
+			name:          "exclude cross-signed and append root",
+			provisioner:   &CertCentral{excludeCrossSigned: true, appendRootToChain: true},
+			expectedChain: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, chainPEM, err := tt.provisioner.encodeChain(fixture.crossSignedBundle)
+			if err != nil {
+				t.Fatalf("encodeChain returned error: %v", err)
+			}
+			chain, err := DecodeCertificates(chainPEM)
+			if err != nil {
+				t.Fatalf("failed to decode chain: %v", err)
+			}
+			if got := certCNs(chain); !reflect.DeepEqual(got, tt.expectedChain) {
+				t.Fatalf("expected chain %v, got %v", tt.expectedChain, got)
+			}
+		})
+	}
+}
+
+func TestCertCentralIncludeRootInCA(t *testing.T) {
+	include, exclude := true, false
+
+	if !(&CertCentral{}).IncludeRootInCA(true) || (&CertCentral{}).IncludeRootInCA(false) {
+		t.Fatalf("expected the default to be used if not configured")
+	}
+	if !(&CertCentral{includeRootInCA: &include}).IncludeRootInCA(false) {
+		t.Fatalf("expected the chain policy to override the default")
+	}
+	if (&CertCentral{includeRootInCA: &exclude}).IncludeRootInCA(true) {
+		t.Fatalf("expected the chain policy to override the default")
+	}
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultChainCacheSize is the default number of issuer chains kept in the chain cache.
+const DefaultChainCacheSize = 64
+
+func init() {
+	metrics.Registry.MustRegister(metricChainCacheHits, metricChainCacheMisses, metricChainCacheInvalidations)
+}
+
+var (
+	metricChainCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_chain_cache_hits_total",
+			Help: "Number of certificate chains completed from the chain cache",
+		},
+	)
+
+	metricChainCacheMisses = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_chain_cache_misses_total",
+			Help: "Number of certificate chains fetched from DigiCert as the issuer was not cached",
+		},
+	)
+
+	metricChainCacheInvalidations = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "digicertissuer_chain_cache_invalidations_total",
+			Help: "Number of cached certificate chains replaced as DigiCert returned a different intermediate",
+		},
+	)
+)
+
+// ChainCache is a size-bounded LRU cache of the certificates issuing a leaf certificate.
+// Entries are keyed by the fingerprint of the issuer of the leaf and additionally indexed by a hint, e.g. the caCertID,
+// to detect when DigiCert issues from a different intermediate for the same CA and to know whether the chain of a
+// pending order is likely cached before downloading it.
+// One cache should be shared by all provisioners as the intermediates do not depend on the issuer configuration.
+type ChainCache struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+	hints   map[string]string
+}
+
+type chainCacheEntry struct {
+	key   string
+	chain []*x509.Certificate
+}
+
+// NewChainCache returns a ChainCache keeping the given number of issuer chains.
+func NewChainCache(size int) *ChainCache {
+	return &ChainCache{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		hints:   make(map[string]string),
+	}
+}
+
+// issuerFingerprint identifies the issuer of the certificate by its authority key ID or, if missing, its name.
+func issuerFingerprint(cert *x509.Certificate) string {
+	if len(cert.AuthorityKeyId) > 0 {
+		return "aki:" + hex.EncodeToString(cert.AuthorityKeyId)
+	}
+	sum := sha256.Sum256(cert.RawIssuer)
+	return "issuer:" + hex.EncodeToString(sum[:])
+}
+
+// get completes the leaf with the cached issuer chain. Returns false if the issuer of the leaf is not cached or the
+// cached chain did not issue the leaf, in which case the entry is removed.
+func (c *ChainCache) get(leaf *x509.Certificate) ([]*x509.Certificate, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[issuerFingerprint(leaf)]
+	if !ok {
+		metricChainCacheMisses.Inc()
+		return nil, false
+	}
+
+	entry := elem.Value.(*chainCacheEntry)
+	if len(entry.chain) == 0 || leaf.CheckSignatureFrom(entry.chain[0]) != nil {
+		c.remove(elem)
+		metricChainCacheInvalidations.Inc()
+		metricChainCacheMisses.Inc()
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	metricChainCacheHits.Inc()
+	chain := make([]*x509.Certificate, 0, len(entry.chain)+1)
+	chain = append(chain, leaf)
+	return append(chain, entry.chain...), true
+}
+
+// has returns true if an issuer chain was cached for the hint. Otherwise, a cache miss is counted.
+func (c *ChainCache) has(hint string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.hints[hint]; ok && hint != "" {
+		if _, ok := c.entries[key]; ok {
+			return true
+		}
+	}
+	metricChainCacheMisses.Inc()
+	return false
+}
+
+// add caches the issuer chain of the normalized certificate chain starting with the leaf.
+// If the hint was last seen with a different issuer, the previous entry is invalidated.
+func (c *ChainCache) add(hint string, chain []*x509.Certificate) {
+	if c == nil || len(chain) < 2 || chain[0].IsCA || chain[0].CheckSignatureFrom(chain[1]) != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := issuerFingerprint(chain[0])
+	if hint != "" {
+		if previous, ok := c.hints[hint]; ok && previous != key {
+			if elem, ok := c.entries[previous]; ok {
+				c.remove(elem)
+				metricChainCacheInvalidations.Inc()
+			}
+		}
+		c.hints[hint] = key
+	}
+
+	issuers := append([]*x509.Certificate(nil), chain[1:]...)
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*chainCacheEntry).chain = issuers
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&chainCacheEntry{key: key, chain: issuers})
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *ChainCache) remove(elem *list.Element) {
+	entry := elem.Value.(*chainCacheEntry)
+	c.lru.Remove(elem)
+	delete(c.entries, entry.key)
+	for hint, key := range c.hints {
+		if key == entry.key {
+			delete(c.hints, hint)
+		}
+	}
+}
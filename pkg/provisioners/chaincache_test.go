@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/x509"
+	"reflect"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestChainCache(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+	otherLeaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+	rotatedLeaf := issueLeaf(t, fixture.csr, fixture.unrelatedRoot, fixture.unrelatedRootKey, nil)
+
+	cache := NewChainCache(DefaultChainCacheSize)
+	if _, ok := cache.get(leaf); ok {
+		t.Fatalf("expected miss on empty cache")
+	}
+
+	cache.add("ca-1", []*x509.Certificate{leaf, fixture.intermediate, fixture.root})
+	if !cache.has("ca-1") || cache.has("ca-2") || cache.has("") {
+		t.Fatalf("expected only the hint of the cached chain to be known")
+	}
+	chain, ok := cache.get(otherLeaf)
+	if !ok {
+		t.Fatalf("expected hit for leaf of cached issuer")
+	}
+	if got, expected := certCNs(chain), []string{"verify.test.local", "Verify Intermediate", "Verify Root"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected chain %v, got %v", expected, got)
+	}
+	if chain[0] != otherLeaf {
+		t.Fatalf("expected chain to start with the requested leaf")
+	}
+
+	// DigiCert returned a different intermediate for the same CA.
+	cache.add("ca-1", []*x509.Certificate{rotatedLeaf, fixture.unrelatedRoot})
+	if _, ok := cache.get(leaf); ok {
+		t.Fatalf("expected previous intermediate to be invalidated")
+	}
+	if _, ok := cache.get(rotatedLeaf); !ok {
+		t.Fatalf("expected hit for leaf of rotated intermediate")
+	}
+
+	// Chains not starting with the leaf signed by the next certificate are not cached.
+	cache.add("ca-2", []*x509.Certificate{leaf, fixture.root})
+	cache.add("ca-2", []*x509.Certificate{fixture.intermediate, fixture.root})
+	if _, ok := cache.get(leaf); ok {
+		t.Fatalf("expected invalid chains not to be cached")
+	}
+}
+
+func TestChainCacheSize(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+	otherLeaf := issueLeaf(t, fixture.csr, fixture.unrelatedRoot, fixture.unrelatedRootKey, nil)
+
+	cache := NewChainCache(1)
+	cache.add("", []*x509.Certificate{leaf, fixture.intermediate})
+	cache.add("", []*x509.Certificate{otherLeaf, fixture.unrelatedRoot})
+
+	if cache.lru.Len() != 1 || len(cache.entries) != 1 {
+		t.Fatalf("expected cache to be bounded to 1 entry, got %d", cache.lru.Len())
+	}
+	if _, ok := cache.get(leaf); ok {
+		t.Fatalf("expected least recently used entry to be evicted")
+	}
+	if _, ok := cache.get(otherLeaf); !ok {
+		t.Fatalf("expected most recently used entry to be cached")
+	}
+}
+
+func TestCertCentralDownloadChainCache(t *testing.T) {
+	fixture := buildChainFixture(t)
+	csrPEM := createCSR(t, fixture.requestedCN)
+
+	mock := &mockCertCentralClient{chain: constructCertificateChain(t, fixture.regularBundle)}
+	provisioner := &CertCentral{
+		client:     mock,
+		recorder:   record.NewFakeRecorder(10),
+		chainCache: NewChainCache(DefaultChainCacheSize),
+	}
+	cr := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "download-test",
+			Annotations: map[string]string{"certmanager.cloud.sap/digicert-cert-id": "12345"},
+		},
+		Spec: certmanagerv1.CertificateRequestSpec{Request: csrPEM},
+	}
+
+	hits := testutil.ToFloat64(metricChainCacheHits)
+	misses := testutil.ToFloat64(metricChainCacheMisses)
+
+	for i := range 3 {
+		caPEM, tlsPEM, err := provisioner.Download(context.Background(), cr)
+		if err != nil {
+			t.Fatalf("Download returned error: %v", err)
+		}
+		assertChainCNs(t, tlsPEM, caPEM, []string{fixture.requestedCN, "Test Intermediate"}, []string{fixture.preferredRoot})
+
+		if mock.chainRequests != 1 {
+			t.Fatalf("download %d: expected the chain to be fetched once, got %d", i, mock.chainRequests)
+		}
+		// The leaf is only downloaded separately once the chain is cached.
+		if mock.downloadRequests != i {
+			t.Fatalf("download %d: expected %d leaf downloads, got %d", i, i, mock.downloadRequests)
+		}
+	}
+
+	if got := testutil.ToFloat64(metricChainCacheMisses) - misses; got != 1 {
+		t.Fatalf("expected 1 cache miss, got %v", got)
+	}
+	if got := testutil.ToFloat64(metricChainCacheHits) - hits; got != 2 {
+		t.Fatalf("expected 2 cache hits, got %v", got)
+	}
+}
@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	certcentral "github.com/sapcc/go-certcentral"
+)
+
+const (
+	defaultCertCentralURL = "https://www.digicert.com/services/v2"
+	contentTypeJSON       = "application/json"
+	// userAgent is the User-Agent sent by go-certcentral, so all requests of the issuer look the same to DigiCert.
+	userAgent = "sapcc/go-certcentral"
+
+	orderStatusChangesPageLimit  = 1000
+	orderStatusChangesTimeFormat = "2006-01-02T15:04:05-07:00"
+)
+
+// orderRequest extends certcentral.Order with fields not supported by go-certcentral.
+type orderRequest struct {
+	certcentral.Order
+	CustomFields     []customFieldValue `json:"custom_fields,omitempty"`
+	AdditionalEmails []string           `json:"additional_emails,omitempty"`
+}
+
+// Order is the response to an order submission including the fields not supported by go-certcentral.
+type Order struct {
+	certcentral.Order
+	// Price and Currency of the order. Only set if returned by CertCentral.
+	Price    float64 `json:"price,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+// OrderStatusChange is an order whose status changed.
+type OrderStatusChange struct {
+	OrderID       int    `json:"order_id"`
+	CertificateID int    `json:"certificate_id"`
+	Status        string `json:"status"`
+}
+
+type customFieldValue struct {
+	MetadataID int    `json:"metadata_id"`
+	Value      string `json:"value"`
+}
+
+// customFieldDefinition is a custom order field configured in the account.
+type customFieldDefinition struct {
+	ID         int    `json:"id"`
+	Label      string `json:"label"`
+	IsRequired bool   `json:"is_required"`
+	IsActive   bool   `json:"is_active"`
+	DataType   string `json:"data_type"`
+}
+
+// certCentralHTTPClient is shared by all provisioners, so connections are reused across issuers
+// and idle connections of replaced provisioners are closed.
+// It uses the TLS configuration of go-certcentral.
+var certCentralHTTPClient = &http.Client{
+	Timeout: time.Minute,
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			CipherSuites: []uint16{
+				tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+				tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			},
+		},
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// apiClient extends the go-certcentral client with API calls it does not support.
+type apiClient struct {
+	*certcentral.Client
+	url        string
+	token      string
+	httpClient *http.Client
+}
+
+func newAPIClient(apiToken string) (*apiClient, error) {
+	client, err := certcentral.New(&certcentral.Options{
+		Token: apiToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiClient{
+		Client:     client,
+		url:        defaultCertCentralURL,
+		token:      apiToken,
+		httpClient: certCentralHTTPClient,
+	}, nil
+}
+
+// SubmitOrder submits an order including the fields not supported by go-certcentral.
+func (c *apiClient) SubmitOrder(ctx context.Context, order orderRequest, orderType certcentral.OrderType) (*Order, error) {
+	body, err := json.Marshal(order)
+	if err != nil {
+		return nil, err
+	}
+
+	var orderRes Order
+	if err := c.doJSON(ctx, http.MethodPost, "/order/certificate/"+orderType.String(), bytes.NewReader(body), &orderRes); err != nil {
+		return nil, err
+	}
+	return &orderRes, nil
+}
+
+// ListCustomFields returns the custom order fields configured in the account.
+func (c *apiClient) ListCustomFields(ctx context.Context) ([]customFieldDefinition, error) {
+	var res struct {
+		Metadata []customFieldDefinition `json:"metadata"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/account/metadata", nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Metadata, nil
+}
+
+// ListOrderStatusChanges returns the orders whose status changed in the given period.
+// All pages are requested.
+func (c *apiClient) ListOrderStatusChanges(ctx context.Context, from, to time.Time) ([]OrderStatusChange, error) {
+	changes := make([]OrderStatusChange, 0)
+	for {
+		query := url.Values{}
+		query.Set("filters[status_last_updated]", from.UTC().Format(orderStatusChangesTimeFormat)+"..."+to.UTC().Format(orderStatusChangesTimeFormat))
+		query.Set("limit", strconv.Itoa(orderStatusChangesPageLimit))
+		query.Set("offset", strconv.Itoa(len(changes)))
+
+		var res struct {
+			Orders []OrderStatusChange `json:"orders"`
+			Page   struct {
+				Total int `json:"total"`
+			} `json:"page"`
+		}
+		if err := c.doJSON(ctx, http.MethodGet, "/order/certificate/status-changes?"+query.Encode(), nil, &res); err != nil {
+			return nil, err
+		}
+		changes = append(changes, res.Orders...)
+		if len(res.Orders) == 0 || len(changes) >= res.Page.Total {
+			return changes, nil
+		}
+	}
+}
+
+// GetCertificateChain returns the certificate chain. The context is not used as go-certcentral does not support it.
+func (c *apiClient) GetCertificateChain(_ context.Context, certID string) ([]certcentral.CertificateChain, error) {
+	return c.Client.GetCertificateChain(certID)
+}
+
+// DownloadCertificate downloads the certificate in the given format.
+// The context is not used as go-certcentral does not support it.
+func (c *apiClient) DownloadCertificate(_ context.Context, certificateID string, certFormat certcentral.CertificateFormat) ([]*x509.Certificate, error) {
+	return c.Client.DownloadCertificate(certificateID, certFormat)
+}
+
+func (c *apiClient) doJSON(ctx context.Context, method, path string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.url, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-DC-DEVKEY", c.token)
+	req.Header.Set("Accept", contentTypeJSON)
+	req.Header.Set("Content-Type", contentTypeJSON)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode >= 400 {
+		return parseAPIError(res, resBody)
+	}
+
+	if v == nil || len(resBody) == 0 {
+		return nil
+	}
+	return json.Unmarshal(resBody, v)
+}
+
+// parseAPIError converts an error response into a certcentral.Error the same way go-certcentral does,
+// which does not export its error handling.
+func parseAPIError(res *http.Response, body []byte) error {
+	apiErr := &certcentral.Error{
+		Code:    res.StatusCode,
+		Status:  res.Status,
+		Message: "unknown error",
+	}
+
+	var errs struct {
+		Errors []certcentral.Error `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &errs); err != nil {
+		return apiErr
+	}
+	if len(errs.Errors) > 0 {
+		apiErr.Status = errs.Errors[0].Status
+		apiErr.Message = errs.Errors[0].Message
+	}
+	return apiErr
+}
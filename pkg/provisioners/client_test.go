@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	certcentral "github.com/sapcc/go-certcentral"
+)
+
+func newTestAPIClient(t *testing.T, handler http.HandlerFunc) *apiClient {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := newAPIClient("test-token")
+	if err != nil {
+		t.Fatalf("newAPIClient returned error: %v", err)
+	}
+	client.url = server.URL
+	client.httpClient = server.Client()
+	return client
+}
+
+func TestNewAPIClientSharesHTTPClient(t *testing.T) {
+	first, err := newAPIClient("first-token")
+	if err != nil {
+		t.Fatalf("newAPIClient returned error: %v", err)
+	}
+	second, err := newAPIClient("second-token")
+	if err != nil {
+		t.Fatalf("newAPIClient returned error: %v", err)
+	}
+	if first.httpClient != second.httpClient {
+		t.Fatal("expected API clients to share the HTTP client")
+	}
+
+	transport, ok := first.httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("unexpected transport %T", first.httpClient.Transport)
+	}
+	if transport.IdleConnTimeout == 0 {
+		t.Fatal("expected idle connections to time out")
+	}
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2 as minimum version, got %+v", transport.TLSClientConfig)
+	}
+}
+
+func TestAPIClientSubmitOrder(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/order/certificate/ssl_securesite_flex" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if token := r.Header.Get("X-DC-DEVKEY"); token != "test-token" {
+			t.Errorf("unexpected API token %q", token)
+		}
+		if ua := r.Header.Get("User-Agent"); ua != userAgent {
+			t.Errorf("unexpected User-Agent %q", ua)
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request body: %v", err)
+		}
+		if body["comments"] != "some comment" {
+			t.Errorf("unexpected comments %v", body["comments"])
+		}
+		fields, ok := body["custom_fields"].([]interface{})
+		if !ok || len(fields) != 1 {
+			t.Errorf("unexpected custom fields %v", body["custom_fields"])
+		}
+
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"id": 42, "certificate_id": 43, "price": 218.5, "currency": "USD"}`))
+	})
+
+	order, err := client.SubmitOrder(context.Background(), orderRequest{
+		Order:        certcentral.Order{Comments: "some comment"},
+		CustomFields: []customFieldValue{{MetadataID: 1, Value: "value"}},
+	}, certcentral.OrderTypes.SecureSiteOV)
+	if err != nil {
+		t.Fatalf("SubmitOrder returned error: %v", err)
+	}
+	if order.ID != 42 || order.CertificateID != 43 || order.Price != 218.5 || order.Currency != "USD" {
+		t.Fatalf("unexpected order response %+v", order)
+	}
+}
+
+func TestAPIClientListCustomFields(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/account/metadata" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		_, _ = w.Write([]byte(`{"metadata": [{"id": 1, "label": "Cost center", "is_required": true, "is_active": true, "data_type": "text"}]}`))
+	})
+
+	fields, err := client.ListCustomFields(context.Background())
+	if err != nil {
+		t.Fatalf("ListCustomFields returned error: %v", err)
+	}
+	expected := []customFieldDefinition{{ID: 1, Label: "Cost center", IsRequired: true, IsActive: true, DataType: "text"}}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("unexpected custom fields, got=%v expected=%v", fields, expected)
+	}
+}
+
+func TestAPIClientListOrderStatusChanges(t *testing.T) {
+	from := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/order/certificate/status-changes" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if period := r.URL.Query().Get("filters[status_last_updated]"); period != "2025-03-01T12:00:00+00:00...2025-03-01T13:00:00+00:00" {
+			t.Errorf("unexpected period %q", period)
+		}
+
+		w.Header().Set("Content-Type", contentTypeJSON)
+		switch offset := r.URL.Query().Get("offset"); offset {
+		case "0":
+			_, _ = w.Write([]byte(`{"orders": [{"order_id": 1, "certificate_id": 11, "status": "issued"}], "page": {"total": 2}}`))
+		case "1":
+			_, _ = w.Write([]byte(`{"orders": [{"order_id": 2, "certificate_id": 12, "status": "rejected"}], "page": {"total": 2}}`))
+		default:
+			t.Errorf("unexpected offset %q", offset)
+		}
+	})
+
+	changes, err := client.ListOrderStatusChanges(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("ListOrderStatusChanges returned error: %v", err)
+	}
+	expected := []OrderStatusChange{
+		{OrderID: 1, CertificateID: 11, Status: "issued"},
+		{OrderID: 2, CertificateID: 12, Status: "rejected"},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("unexpected order status changes, got=%v expected=%v", changes, expected)
+	}
+}
+
+func TestAPIClientError(t *testing.T) {
+	client := newTestAPIClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors": [{"code": "invalid_custom_field", "message": "custom field is required"}]}`))
+	})
+
+	_, err := client.SubmitOrder(context.Background(), orderRequest{}, certcentral.OrderTypes.SecureSiteOV)
+	var apiErr *certcentral.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected certcentral.Error, got %v", err)
+	}
+	if apiErr.Code != http.StatusBadRequest || apiErr.Status != "invalid_custom_field" {
+		t.Fatalf("unexpected error %+v", apiErr)
+	}
+}
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/x509"
+	"time"
+
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxIssuerIntermediates limits the number of intermediates kept in the issuer status.
+const maxIssuerIntermediates = 10
+
+// IssuingIntermediate returns the certificate of the PEM encoded chain that signed the leaf.
+// Returns nil if the chain doesn't contain it.
+func IssuingIntermediate(chainPEM []byte) (*x509.Certificate, error) {
+	chain, err := DecodeCertificates(chainPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf := findLeaf(chain)
+	if leaf == nil {
+		return nil, nil
+	}
+	return findIssuer(leaf, chain, []*x509.Certificate{leaf}), nil
+}
+
+// RecordIntermediate marks the intermediate as current in the issuer status and adds it if it was not seen before.
+// Returns the previously current intermediate if the intermediate was rotated and whether the status was changed.
+func RecordIntermediate(status *v1beta1.DigicertIssuerStatus, intermediate *x509.Certificate, now time.Time) (*v1beta1.IssuerIntermediate, bool) {
+	var (
+		fp       = fingerprint(intermediate.Raw)
+		previous *v1beta1.IssuerIntermediate
+		found    bool
+	)
+	for idx := range status.Intermediates {
+		cur := &status.Intermediates[idx]
+		switch {
+		case cur.Fingerprint == fp:
+			if cur.Current {
+				return nil, false
+			}
+			cur.Current, found = true, true
+		case cur.Current:
+			cur.Current = false
+			previous = cur.DeepCopy()
+		}
+	}
+
+	if !found {
+		status.Intermediates = append(status.Intermediates, v1beta1.IssuerIntermediate{
+			CommonName:  intermediate.Subject.CommonName,
+			Fingerprint: fp,
+			FirstSeen:   metav1.NewTime(now.UTC()),
+			Current:     true,
+		})
+	}
+
+	// Forget the intermediates seen first. The current one was either appended or already known.
+	if len(status.Intermediates) > maxIssuerIntermediates {
+		status.Intermediates = status.Intermediates[len(status.Intermediates)-maxIssuerIntermediates:]
+	}
+
+	return previous, true
+}
@@ -0,0 +1,98 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+func TestIssuingIntermediate(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+
+	chainPEM, err := EncodePEM([]*x509.Certificate{leaf, fixture.intermediate})
+	if err != nil {
+		t.Fatalf("failed to encode chain: %v", err)
+	}
+	intermediate, err := IssuingIntermediate(chainPEM)
+	if err != nil {
+		t.Fatalf("IssuingIntermediate returned error: %v", err)
+	}
+	if intermediate == nil || !intermediate.Equal(fixture.intermediate) {
+		t.Fatalf("expected the intermediate, got %v", intermediate)
+	}
+
+	leafPEM, err := EncodePEM([]*x509.Certificate{leaf})
+	if err != nil {
+		t.Fatalf("failed to encode leaf: %v", err)
+	}
+	if intermediate, err := IssuingIntermediate(leafPEM); err != nil || intermediate != nil {
+		t.Fatalf("expected no intermediate, got %v, %v", intermediate, err)
+	}
+}
+
+func TestRecordIntermediate(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	reissued := generateCert(t, certSpec{cn: "Reissued Intermediate", isCA: true}, fixture.root, fixture.rootKey, fixture.intermediateKey)
+
+	var (
+		status = &v1beta1.DigicertIssuerStatus{}
+		now    = time.Now()
+	)
+
+	previous, changed := RecordIntermediate(status, fixture.intermediate, now)
+	if !changed || previous != nil {
+		t.Fatalf("expected the first intermediate to be added without rotation, got changed=%t previous=%v", changed, previous)
+	}
+
+	previous, changed = RecordIntermediate(status, fixture.intermediate, now.Add(time.Hour))
+	if changed || previous != nil {
+		t.Fatalf("expected no change for the current intermediate, got changed=%t previous=%v", changed, previous)
+	}
+
+	previous, changed = RecordIntermediate(status, reissued, now.Add(time.Hour))
+	if !changed || previous == nil || previous.CommonName != "Verify Intermediate" {
+		t.Fatalf("expected rotation from the previous intermediate, got changed=%t previous=%v", changed, previous)
+	}
+	if len(status.Intermediates) != 2 || status.Intermediates[0].Current || !status.Intermediates[1].Current {
+		t.Fatalf("expected the reissued intermediate to be current, got %+v", status.Intermediates)
+	}
+
+	// Rotating back keeps the time the intermediate was first seen.
+	previous, changed = RecordIntermediate(status, fixture.intermediate, now.Add(2*time.Hour))
+	if !changed || previous == nil || previous.CommonName != "Reissued Intermediate" {
+		t.Fatalf("expected rotation back to the first intermediate, got changed=%t previous=%v", changed, previous)
+	}
+	if !status.Intermediates[0].Current || !status.Intermediates[0].FirstSeen.Time.Equal(now) {
+		t.Fatalf("expected the first intermediate to be current with unchanged first seen time, got %+v", status.Intermediates[0])
+	}
+}
+
+func TestRecordIntermediateLimit(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+
+	status := &v1beta1.DigicertIssuerStatus{}
+	for idx := 0; idx < maxIssuerIntermediates; idx++ {
+		status.Intermediates = append(status.Intermediates, v1beta1.IssuerIntermediate{Fingerprint: fmt.Sprintf("%d", idx)})
+	}
+	status.Intermediates[maxIssuerIntermediates-1].Current = true
+
+	if previous, changed := RecordIntermediate(status, fixture.intermediate, time.Now()); !changed || previous == nil {
+		t.Fatalf("expected rotation, got changed=%t previous=%v", changed, previous)
+	}
+	if len(status.Intermediates) != maxIssuerIntermediates {
+		t.Fatalf("expected %d intermediates, got %d", maxIssuerIntermediates, len(status.Intermediates))
+	}
+	if status.Intermediates[0].Fingerprint != "1" {
+		t.Fatalf("expected the intermediate seen first to be removed, got %+v", status.Intermediates[0])
+	}
+	if cur := status.Intermediates[maxIssuerIntermediates-1]; !cur.Current || cur.Fingerprint != fingerprint(fixture.intermediate.Raw) {
+		t.Fatalf("expected the new intermediate to be current, got %+v", cur)
+	}
+}
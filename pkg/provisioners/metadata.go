@@ -0,0 +1,186 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+// orderMetadata is the data available to the order comments and custom field templates.
+type orderMetadata struct {
+	ClusterName     string
+	Namespace       string
+	CertificateName string
+	RequestName     string
+	RequestUID      string
+	Annotations     map[string]string
+}
+
+func newOrderMetadata(cr *certmanagerv1.CertificateRequest, clusterName string) orderMetadata {
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	return orderMetadata{
+		ClusterName:     clusterName,
+		Namespace:       cr.GetNamespace(),
+		CertificateName: annotations[certmanagerv1.CertificateNameKey],
+		RequestName:     cr.GetName(),
+		RequestUID:      string(cr.GetUID()),
+		Annotations:     annotations,
+	}
+}
+
+// OrderTemplateError is returned if the order comments or a custom field cannot be rendered for a
+// CertificateRequest, e.g. because it lacks a referenced annotation.
+type OrderTemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e *OrderTemplateError) Error() string {
+	return fmt.Sprintf("failed to render %s: %v", e.Template, e.Err)
+}
+
+func (e *OrderTemplateError) Unwrap() error {
+	return e.Err
+}
+
+type orderTemplates struct {
+	comments     *template.Template
+	customFields []customFieldTemplate
+}
+
+type customFieldTemplate struct {
+	metadataID int
+	value      *template.Template
+}
+
+func newOrderTemplates(provisionerSpec v1beta1.DigicertProvisioner) (*orderTemplates, error) {
+	t := new(orderTemplates)
+
+	if provisionerSpec.OrderComments != "" {
+		tmpl, err := template.New("orderComments").Option("missingkey=error").Parse(provisionerSpec.OrderComments)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.provisioner.orderComments: %w", err)
+		}
+		t.comments = tmpl
+	}
+
+	for idx, field := range provisionerSpec.CustomFields {
+		tmpl, err := template.New(fmt.Sprintf("customFields[%d]", idx)).Option("missingkey=error").Parse(field.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec.provisioner.customFields[%d].value: %w", idx, err)
+		}
+		t.customFields = append(t.customFields, customFieldTemplate{metadataID: field.MetadataID, value: tmpl})
+	}
+
+	return t, nil
+}
+
+// ValidateOrderTemplates parses the order comments and custom field templates and renders them with example
+// metadata to catch references to unknown fields.
+// Missing annotations are not reported as they depend on the CertificateRequest. Rendering fails on those instead.
+func ValidateOrderTemplates(provisionerSpec v1beta1.DigicertProvisioner) error {
+	t, err := newOrderTemplates(provisionerSpec)
+	if err != nil {
+		return err
+	}
+	if t.comments != nil {
+		t.comments.Option("missingkey=default")
+	}
+	for _, field := range t.customFields {
+		field.value.Option("missingkey=default")
+	}
+
+	_, _, err = t.render(orderMetadata{
+		ClusterName:     "cluster",
+		Namespace:       "namespace",
+		CertificateName: "certificate",
+		RequestName:     "certificate-1",
+		RequestUID:      "00000000-0000-0000-0000-000000000000",
+		Annotations:     map[string]string{},
+	})
+	return err
+}
+
+// render returns the order comments and custom field values for the given metadata.
+func (t *orderTemplates) render(data orderMetadata) (string, []customFieldValue, error) {
+	if t == nil {
+		return "", nil, nil
+	}
+
+	var comments string
+	if t.comments != nil {
+		rendered, err := execute(t.comments, data)
+		if err != nil {
+			return "", nil, err
+		}
+		comments = rendered
+	}
+
+	customFields := make([]customFieldValue, 0, len(t.customFields))
+	for _, field := range t.customFields {
+		value, err := execute(field.value, data)
+		if err != nil {
+			return "", nil, err
+		}
+		customFields = append(customFields, customFieldValue{MetadataID: field.metadataID, Value: value})
+	}
+
+	return comments, customFields, nil
+}
+
+func execute(tmpl *template.Template, data orderMetadata) (string, error) {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", &OrderTemplateError{Template: tmpl.Name(), Err: err}
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ValidateCustomFields compares the configured custom order fields with the custom order fields of the account.
+// Unknown or inactive fields and required fields without a configured value are returned as error.
+func (c *CertCentral) ValidateCustomFields(ctx context.Context) error {
+	definitions, err := c.client.ListCustomFields(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list custom order fields: %w", err)
+	}
+
+	configured := make(map[int]bool)
+	var errs error
+	if c.orderTemplates != nil {
+		definitionsByID := make(map[int]customFieldDefinition, len(definitions))
+		for _, definition := range definitions {
+			definitionsByID[definition.ID] = definition
+		}
+
+		for _, field := range c.orderTemplates.customFields {
+			configured[field.metadataID] = true
+			definition, ok := definitionsByID[field.metadataID]
+			switch {
+			case !ok:
+				errs = multierror.Append(errs, fmt.Errorf("custom order field with metadataID %d does not exist", field.metadataID))
+			case !definition.IsActive:
+				errs = multierror.Append(errs, fmt.Errorf("custom order field %q (metadataID %d) is not active", definition.Label, definition.ID))
+			}
+		}
+	}
+
+	for _, definition := range definitions {
+		if definition.IsActive && definition.IsRequired && !configured[definition.ID] {
+			errs = multierror.Append(errs, fmt.Errorf("required custom order field %q (metadataID %d) missing", definition.Label, definition.ID))
+		}
+	}
+
+	return errs
+}
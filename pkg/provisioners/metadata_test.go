@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	certcentral "github.com/sapcc/go-certcentral"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestValidateOrderTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      v1beta1.DigicertProvisioner
+		expectErr bool
+	}{
+		{
+			name: "valid",
+			spec: v1beta1.DigicertProvisioner{
+				OrderComments: "{{ .ClusterName }}/{{ .Namespace }}/{{ .CertificateName }}",
+				CustomFields: []v1beta1.CustomField{
+					{MetadataID: 1, Value: `{{ index .Annotations "team" }}`},
+				},
+			},
+		},
+		{
+			name:      "invalid_syntax",
+			spec:      v1beta1.DigicertProvisioner{OrderComments: "{{ .Namespace "},
+			expectErr: true,
+		},
+		{
+			name: "unknown_field",
+			spec: v1beta1.DigicertProvisioner{
+				CustomFields: []v1beta1.CustomField{{MetadataID: 1, Value: "{{ .Owner }}"}},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOrderTemplates(tt.spec)
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCertCentralSignOrderMetadata(t *testing.T) {
+	fixture := buildChainFixture(t)
+	csrPEM := createCSR(t, fixture.requestedCN)
+
+	templates, err := newOrderTemplates(v1beta1.DigicertProvisioner{
+		OrderComments: "{{ .ClusterName }}/{{ .Namespace }}/{{ .CertificateName }} ({{ .RequestUID }})",
+		CustomFields: []v1beta1.CustomField{
+			{MetadataID: 7, Value: "{{ .RequestName }}"},
+			{MetadataID: 8, Value: `{{ index .Annotations "example.com/team" }}`},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newOrderTemplates returned error: %v", err)
+	}
+
+	mock := &mockCertCentralClient{submitOrder: &certcentral.Order{CertificateChain: constructCertificateChain(t, fixture.regularBundle)}}
+	provisioner := &CertCentral{
+		client:           mock,
+		orderTemplates:   templates,
+		additionalEmails: []string{"team@example.com"},
+		recorder:         record.NewFakeRecorder(10),
+	}
+
+	cr := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "metadata-test-1",
+			Namespace: "team-a",
+			UID:       "1234",
+			Annotations: map[string]string{
+				certmanagerv1.CertificateNameKey: "metadata-test",
+				"example.com/team":               "blue",
+			},
+		},
+		Spec: certmanagerv1.CertificateRequestSpec{Request: csrPEM},
+	}
+
+	if _, _, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{ClusterName: "eu-1"}); err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	if len(mock.submitted) != 1 {
+		t.Fatalf("expected exactly one submitted order, got %d", len(mock.submitted))
+	}
+
+	order := mock.submitted[0]
+	if expected := "eu-1/team-a/metadata-test (1234)"; order.Comments != expected {
+		t.Fatalf("unexpected comments, got=%q expected=%q", order.Comments, expected)
+	}
+	expectedFields := []customFieldValue{
+		{MetadataID: 7, Value: "metadata-test-1"},
+		{MetadataID: 8, Value: "blue"},
+	}
+	if !reflect.DeepEqual(order.CustomFields, expectedFields) {
+		t.Fatalf("unexpected custom fields, got=%v expected=%v", order.CustomFields, expectedFields)
+	}
+	if !reflect.DeepEqual(order.AdditionalEmails, []string{"team@example.com"}) {
+		t.Fatalf("unexpected additional emails, got=%v", order.AdditionalEmails)
+	}
+}
+
+func TestOrderTemplatesMissingAnnotation(t *testing.T) {
+	spec := v1beta1.DigicertProvisioner{
+		OrderComments: "owned by {{ .Annotations.team }}",
+	}
+	if err := ValidateOrderTemplates(spec); err != nil {
+		t.Fatalf("ValidateOrderTemplates returned error: %v", err)
+	}
+
+	templates, err := newOrderTemplates(spec)
+	if err != nil {
+		t.Fatalf("newOrderTemplates returned error: %v", err)
+	}
+
+	comments, _, err := templates.render(orderMetadata{Annotations: map[string]string{"team": "blue"}})
+	if err != nil {
+		t.Fatalf("render returned error: %v", err)
+	}
+	if comments != "owned by blue" {
+		t.Fatalf("unexpected comments %q", comments)
+	}
+
+	_, _, err = templates.render(orderMetadata{Annotations: map[string]string{}})
+	var templateErr *OrderTemplateError
+	if !errors.As(err, &templateErr) {
+		t.Fatalf("expected OrderTemplateError, got %v", err)
+	}
+	if templateErr.Template != "orderComments" {
+		t.Fatalf("unexpected template %q", templateErr.Template)
+	}
+}
+
+func TestCertCentralValidateCustomFields(t *testing.T) {
+	definitions := []customFieldDefinition{
+		{ID: 1, Label: "Cost center", IsRequired: true, IsActive: true},
+		{ID: 2, Label: "Owner", IsRequired: false, IsActive: true},
+		{ID: 3, Label: "Legacy", IsRequired: true, IsActive: false},
+	}
+
+	tests := []struct {
+		name         string
+		customFields []v1beta1.CustomField
+		expectErr    bool
+	}{
+		{
+			name:         "required_field_configured",
+			customFields: []v1beta1.CustomField{{MetadataID: 1, Value: "cc-1234"}},
+		},
+		{
+			name:         "required_field_missing",
+			customFields: []v1beta1.CustomField{{MetadataID: 2, Value: "{{ .Namespace }}"}},
+			expectErr:    true,
+		},
+		{
+			name:         "unknown_field",
+			customFields: []v1beta1.CustomField{{MetadataID: 1, Value: "cc-1234"}, {MetadataID: 4, Value: "x"}},
+			expectErr:    true,
+		},
+		{
+			name:         "inactive_field",
+			customFields: []v1beta1.CustomField{{MetadataID: 1, Value: "cc-1234"}, {MetadataID: 3, Value: "x"}},
+			expectErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates, err := newOrderTemplates(v1beta1.DigicertProvisioner{CustomFields: tt.customFields})
+			if err != nil {
+				t.Fatalf("newOrderTemplates returned error: %v", err)
+			}
+			provisioner := &CertCentral{client: &mockCertCentralClient{customFields: definitions}, orderTemplates: templates}
+
+			err = provisioner.ValidateCustomFields(context.Background())
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
+	certcentral "github.com/sapcc/go-certcentral"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	endpointSubmitOrder         = "POST /order/certificate/{order_type}"
+	endpointGetCertificateChain = "GET /certificate/{certificate_id}/chain"
+	endpointDownloadCertificate = "GET /certificate/{certificate_id}/download/format/{format}"
+	endpointListCustomFields    = "GET /account/metadata"
+	endpointListOrderStatus     = "GET /order/certificate/status-changes"
+)
+
+func init() {
+	metrics.Registry.MustRegister(metricAPIRequestDuration)
+}
+
+var (
+	metricAPIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "digicertissuer_certcentral_request_duration_seconds",
+			Help:    "Duration of CertCentral API requests",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{
+			"endpoint",
+			"code",
+		},
+	)
+)
+
+// instrumentedClient records the duration of all CertCentral API requests as metrics and spans.
+type instrumentedClient struct {
+	certCentralClient
+	issuer string
+}
+
+func (c *instrumentedClient) SubmitOrder(ctx context.Context, order orderRequest, orderType certcentral.OrderType) (*Order, error) {
+	ctx, done := c.observe(ctx, endpointSubmitOrder, attribute.String("digicert.order_type", orderType.String()))
+	res, err := c.certCentralClient.SubmitOrder(ctx, order, orderType)
+	if res != nil {
+		trace.SpanFromContext(ctx).SetAttributes(tracing.AttributeOrderID.String(strconv.Itoa(res.ID)), tracing.AttributeCertificateID.String(strconv.Itoa(res.CertificateID)))
+	}
+	done(err)
+	return res, err
+}
+
+func (c *instrumentedClient) GetCertificateChain(ctx context.Context, certID string) ([]certcentral.CertificateChain, error) {
+	ctx, done := c.observe(ctx, endpointGetCertificateChain, tracing.AttributeCertificateID.String(certID))
+	res, err := c.certCentralClient.GetCertificateChain(ctx, certID)
+	done(err)
+	return res, err
+}
+
+func (c *instrumentedClient) DownloadCertificate(ctx context.Context, certificateID string, certFormat certcentral.CertificateFormat) ([]*x509.Certificate, error) {
+	ctx, done := c.observe(ctx, endpointDownloadCertificate, tracing.AttributeCertificateID.String(certificateID))
+	res, err := c.certCentralClient.DownloadCertificate(ctx, certificateID, certFormat)
+	done(err)
+	return res, err
+}
+
+func (c *instrumentedClient) ListCustomFields(ctx context.Context) ([]customFieldDefinition, error) {
+	ctx, done := c.observe(ctx, endpointListCustomFields)
+	res, err := c.certCentralClient.ListCustomFields(ctx)
+	done(err)
+	return res, err
+}
+
+func (c *instrumentedClient) ListOrderStatusChanges(ctx context.Context, from, to time.Time) ([]OrderStatusChange, error) {
+	ctx, done := c.observe(ctx, endpointListOrderStatus)
+	res, err := c.certCentralClient.ListOrderStatusChanges(ctx, from, to)
+	done(err)
+	return res, err
+}
+
+// observe starts a span for the request. The returned function records the duration and ends the span.
+// The status code is only known for error responses, so successful requests are recorded as 2xx
+// and requests without response as error.
+func (c *instrumentedClient) observe(ctx context.Context, endpoint string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, endpoint, append(attrs, tracing.AttributeIssuer.String(c.issuer))...)
+	return ctx, func(err error) {
+		code := apiStatusCode(err)
+		metricAPIRequestDuration.WithLabelValues(endpoint, code).Observe(time.Since(start).Seconds())
+		span.SetAttributes(attribute.String("http.response.status_code", code))
+		tracing.End(span, err)
+	}
+}
+
+func apiStatusCode(err error) string {
+	if err == nil {
+		return "2xx"
+	}
+
+	var apiErr *certcentral.Error
+	if errors.As(err, &apiErr) && apiErr.Code > 0 {
+		return strconv.Itoa(apiErr.Code)
+	}
+	return "error"
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	certcentral "github.com/sapcc/go-certcentral"
+)
+
+func TestInstrumentedClient(t *testing.T) {
+	client := &instrumentedClient{
+		certCentralClient: &mockCertCentralClient{submitOrder: &certcentral.Order{ID: 1}},
+		issuer:            "default/metrics-test",
+	}
+
+	if _, err := client.SubmitOrder(context.Background(), orderRequest{}, certcentral.OrderTypes.SecureSiteOV); err != nil {
+		t.Fatalf("SubmitOrder returned error: %v", err)
+	}
+
+	client.certCentralClient = &mockCertCentralClient{submitErr: &certcentral.Error{Code: 429}}
+	if _, err := client.SubmitOrder(context.Background(), orderRequest{}, certcentral.OrderTypes.SecureSiteOV); err == nil {
+		t.Fatalf("expected error")
+	}
+	if got := testutil.CollectAndCount(metricAPIRequestDuration, "digicertissuer_certcentral_request_duration_seconds"); got < 2 {
+		t.Fatalf("expected request durations by status code, got %d series", got)
+	}
+}
+
+func TestAPIStatusCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{err: nil, expected: "2xx"},
+		{err: &certcentral.Error{Code: 404}, expected: "404"},
+		{err: fmt.Errorf("wrapped: %w", &certcentral.Error{Code: 500}), expected: "500"},
+		{err: errors.New("connection refused"), expected: "error"},
+	}
+
+	for _, tt := range tests {
+		if got := apiStatusCode(tt.err); got != tt.expected {
+			t.Fatalf("expected %q for %v, got %q", tt.expected, tt.err, got)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// accountID identifies the CertCentral account of an API token without revealing it.
+func accountID(apiToken string) string {
+	sum := sha256.Sum256([]byte(apiToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Account identifies the CertCentral account of the provisioner. Provisioners sharing the API token return the same value.
+func (c *CertCentral) Account() string {
+	return c.account
+}
+
+// ListOrderStatusChanges returns the orders of the account whose status changed in the given period.
+func (c *CertCentral) ListOrderStatusChanges(ctx context.Context, from, to time.Time) ([]OrderStatusChange, error) {
+	return c.client.ListOrderStatusChanges(ctx, from, to)
+}
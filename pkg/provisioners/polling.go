@@ -0,0 +1,109 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+const (
+	// AnnotationKeyPollAttempts is the number of times the pending order of a CertificateRequest was scheduled to be polled.
+	AnnotationKeyPollAttempts = "certmanager.cloud.sap/digicert-poll-attempts"
+	// AnnotationKeyNextPoll is the time the pending order of a CertificateRequest is polled next.
+	AnnotationKeyNextPoll = "certmanager.cloud.sap/digicert-next-poll"
+
+	defaultPollInitialInterval = time.Minute
+	defaultPollMaxInterval     = 6 * time.Hour
+	defaultPollFactor          = 2
+)
+
+type pollingPolicy struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	factor          int
+}
+
+func newPollingPolicy(spec *v1beta1.PollingPolicy) pollingPolicy {
+	p := pollingPolicy{
+		initialInterval: defaultPollInitialInterval,
+		maxInterval:     defaultPollMaxInterval,
+		factor:          defaultPollFactor,
+	}
+	if spec == nil {
+		return p
+	}
+	if spec.InitialInterval != nil {
+		p.initialInterval = spec.InitialInterval.Duration
+	}
+	if spec.MaxInterval != nil {
+		p.maxInterval = spec.MaxInterval.Duration
+	}
+	if spec.Factor != nil {
+		p.factor = *spec.Factor
+	}
+	return p
+}
+
+// interval returns the interval before the given attempt, starting at 1.
+func (p pollingPolicy) interval(attempt int) time.Duration {
+	interval := p.initialInterval
+	for i := 1; i < attempt && interval < p.maxInterval; i++ {
+		interval *= time.Duration(p.factor)
+	}
+	return min(interval, p.maxInterval)
+}
+
+// SchedulePoll records the next attempt to poll the pending order in the annotations of the CertificateRequest
+// and returns the interval until it is due. The interval is at least minInterval.
+func (c *CertCentral) SchedulePoll(cr *certmanagerv1.CertificateRequest, now time.Time, minInterval time.Duration) time.Duration {
+	attempts, _ := strconv.Atoi(cr.GetAnnotations()[AnnotationKeyPollAttempts])
+	attempts++
+	interval := max(c.polling.interval(attempts), minInterval)
+
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[AnnotationKeyPollAttempts] = strconv.Itoa(attempts)
+	annotations[AnnotationKeyNextPoll] = now.Add(interval).UTC().Format(time.RFC3339)
+	cr.SetAnnotations(annotations)
+	return interval
+}
+
+// PollDueIn returns the duration until the pending order of the CertificateRequest is due to be polled.
+// Returns zero or less if the poll is due or no poll was scheduled.
+func PollDueIn(cr *certmanagerv1.CertificateRequest, now time.Time) time.Duration {
+	next, err := time.Parse(time.RFC3339, cr.GetAnnotations()[AnnotationKeyNextPoll])
+	if err != nil {
+		return 0
+	}
+	return next.Sub(now)
+}
+
+// ValidatePollingPolicy validates the intervals and factor of the polling policy.
+func ValidatePollingPolicy(spec *v1beta1.PollingPolicy) error {
+	if spec == nil {
+		return nil
+	}
+
+	var errs error
+	p := newPollingPolicy(spec)
+	if p.initialInterval < time.Second {
+		errs = multierror.Append(errs, errors.New("spec.polling.initialInterval must be at least 1s"))
+	}
+	if p.maxInterval < p.initialInterval {
+		errs = multierror.Append(errs, fmt.Errorf("spec.polling.maxInterval must not be less than the initial interval %s", p.initialInterval))
+	}
+	if p.factor < 1 {
+		errs = multierror.Append(errs, errors.New("spec.polling.factor must be at least 1"))
+	}
+	return errs
+}
@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+)
+
+func TestSchedulePoll(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      *v1beta1.PollingPolicy
+		intervals []time.Duration
+	}{
+		{
+			name: "defaults",
+			intervals: []time.Duration{
+				time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 32 * time.Minute,
+				64 * time.Minute, 128 * time.Minute, 256 * time.Minute, 6 * time.Hour, 6 * time.Hour,
+			},
+		},
+		{
+			name: "custom",
+			spec: &v1beta1.PollingPolicy{
+				InitialInterval: &metav1.Duration{Duration: 10 * time.Second},
+				MaxInterval:     &metav1.Duration{Duration: time.Minute},
+				Factor:          ptr.To(3),
+			},
+			intervals: []time.Duration{10 * time.Second, 30 * time.Second, time.Minute, time.Minute},
+		},
+		{
+			name: "constant",
+			spec: &v1beta1.PollingPolicy{
+				Factor: ptr.To(1),
+			},
+			intervals: []time.Duration{time.Minute, time.Minute, time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CertCentral{polling: newPollingPolicy(tt.spec)}
+			clock := clocktesting.NewFakeClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+			cr := &certmanagerv1.CertificateRequest{}
+
+			if dueIn := PollDueIn(cr, clock.Now()); dueIn > 0 {
+				t.Fatalf("expected unscheduled poll to be due, got %s", dueIn)
+			}
+			for i, expected := range tt.intervals {
+				if interval := c.SchedulePoll(cr, clock.Now(), 0); interval != expected {
+					t.Fatalf("attempt %d: expected interval %s, got %s", i+1, expected, interval)
+				}
+				if dueIn := PollDueIn(cr, clock.Now()); dueIn != expected {
+					t.Fatalf("attempt %d: expected poll due in %s, got %s", i+1, expected, dueIn)
+				}
+				clock.Step(expected - time.Second)
+				if dueIn := PollDueIn(cr, clock.Now()); dueIn != time.Second {
+					t.Fatalf("attempt %d: expected poll due in 1s, got %s", i+1, dueIn)
+				}
+				clock.Step(time.Second)
+				if dueIn := PollDueIn(cr, clock.Now()); dueIn > 0 {
+					t.Fatalf("attempt %d: expected poll to be due, got %s", i+1, dueIn)
+				}
+			}
+			if attempts := cr.GetAnnotations()[AnnotationKeyPollAttempts]; attempts != strconv.Itoa(len(tt.intervals)) {
+				t.Fatalf("expected %d attempts, got %s", len(tt.intervals), attempts)
+			}
+		})
+	}
+}
+
+func TestSchedulePollMinInterval(t *testing.T) {
+	c := &CertCentral{polling: newPollingPolicy(nil)}
+	clock := clocktesting.NewFakeClock(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	cr := &certmanagerv1.CertificateRequest{}
+
+	if interval := c.SchedulePoll(cr, clock.Now(), time.Hour); interval != time.Hour {
+		t.Fatalf("expected interval 1h, got %s", interval)
+	}
+	if dueIn := PollDueIn(cr, clock.Now()); dueIn != time.Hour {
+		t.Fatalf("expected poll due in 1h, got %s", dueIn)
+	}
+}
+
+func TestValidatePollingPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *v1beta1.PollingPolicy
+		wantErr bool
+	}{
+		{name: "nil"},
+		{name: "defaults", spec: &v1beta1.PollingPolicy{}},
+		{
+			name:    "initial interval too short",
+			spec:    &v1beta1.PollingPolicy{InitialInterval: &metav1.Duration{Duration: time.Millisecond}},
+			wantErr: true,
+		},
+		{
+			name:    "max interval below initial interval",
+			spec:    &v1beta1.PollingPolicy{MaxInterval: &metav1.Duration{Duration: time.Second}},
+			wantErr: true,
+		},
+		{
+			name:    "factor below 1",
+			spec:    &v1beta1.PollingPolicy{Factor: ptr.To(0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePollingPolicy(tt.spec); (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %t, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
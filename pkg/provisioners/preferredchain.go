@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+// AnnotationKeyPreferredChain records the preferred chain selected for a CertificateRequest.
+const AnnotationKeyPreferredChain = "certmanager.cloud.sap/digicert-preferred-chain"
+
+// preferredChainDefault is recorded if no preferred chain matched and the default chain is used.
+const preferredChainDefault = "default"
+
+// chainPreference matches the root of a trust chain by common name or fingerprint.
+type chainPreference struct {
+	rootCommonName         string
+	spkiFingerprint        string
+	certificateFingerprint string
+}
+
+func newChainPreferences(provisionerSpec v1beta1.DigicertProvisioner) []chainPreference {
+	if len(provisionerSpec.PreferredChains) == 0 {
+		if provisionerSpec.PreferredChain == "" {
+			return nil
+		}
+		return []chainPreference{{rootCommonName: provisionerSpec.PreferredChain}}
+	}
+
+	preferences := make([]chainPreference, 0, len(provisionerSpec.PreferredChains))
+	for _, pref := range provisionerSpec.PreferredChains {
+		preferences = append(preferences, chainPreference{
+			rootCommonName:         pref.RootCommonName,
+			spkiFingerprint:        normalizeFingerprint(pref.SPKIFingerprint),
+			certificateFingerprint: normalizeFingerprint(pref.CertificateFingerprint),
+		})
+	}
+	return preferences
+}
+
+// matches returns true if the self-signed root matches the preference.
+func (p chainPreference) matches(root *x509.Certificate) bool {
+	if !isSelfSigned(root) {
+		return false
+	}
+	switch {
+	case p.rootCommonName != "":
+		return root.Subject.CommonName == p.rootCommonName
+	case p.spkiFingerprint != "":
+		return fingerprint(root.RawSubjectPublicKeyInfo) == p.spkiFingerprint
+	case p.certificateFingerprint != "":
+		return fingerprint(root.Raw) == p.certificateFingerprint
+	default:
+		return false
+	}
+}
+
+func (p chainPreference) String() string {
+	switch {
+	case p.rootCommonName != "":
+		return "rootCommonName=" + p.rootCommonName
+	case p.spkiFingerprint != "":
+		return "spkiFingerprint=" + p.spkiFingerprint
+	default:
+		return "certificateFingerprint=" + p.certificateFingerprint
+	}
+}
+
+func chainPreferencesString(preferences []chainPreference) string {
+	out := make([]string, len(preferences))
+	for idx, pref := range preferences {
+		out[idx] = pref.String()
+	}
+	return strings.Join(out, ", ")
+}
+
+// fingerprint returns the lower case hex encoded SHA-256 digest of the data.
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeFingerprint accepts fingerprints in upper or lower case, optionally separated by colons.
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fp), ":", ""))
+}
+
+func validateChainPreferences(provisionerSpec v1beta1.DigicertProvisioner) error {
+	var errs error
+	if provisionerSpec.PreferredChain != "" && len(provisionerSpec.PreferredChains) > 0 {
+		errs = multierror.Append(errs, errors.New("spec.provisioner.preferredChain and spec.provisioner.preferredChains are mutually exclusive"))
+	}
+
+	for idx, pref := range provisionerSpec.PreferredChains {
+		set := 0
+		for _, v := range []string{pref.RootCommonName, pref.SPKIFingerprint, pref.CertificateFingerprint} {
+			if v != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.preferredChains[%d] requires exactly one of rootCommonName, spkiFingerprint or certificateFingerprint", idx))
+			continue
+		}
+
+		for name, fp := range map[string]string{"spkiFingerprint": pref.SPKIFingerprint, "certificateFingerprint": pref.CertificateFingerprint} {
+			if fp == "" {
+				continue
+			}
+			if b, err := hex.DecodeString(normalizeFingerprint(fp)); err != nil || len(b) != sha256.Size {
+				errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.preferredChains[%d].%s is not a hex encoded SHA-256 fingerprint", idx, name))
+			}
+		}
+	}
+
+	return errs
+}
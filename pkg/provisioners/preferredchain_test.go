@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	certcentral "github.com/sapcc/go-certcentral"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// colonFingerprint formats the fingerprint in upper case separated by colons as printed by openssl.
+func colonFingerprint(data []byte) string {
+	fp := strings.ToUpper(fingerprint(data))
+	parts := make([]string, 0, len(fp)/2)
+	for i := 0; i < len(fp); i += 2 {
+		parts = append(parts, fp[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+func TestCertCentralSignPreferredChains(t *testing.T) {
+	fixture := buildChainFixture(t)
+	csrPEM := createCSR(t, fixture.requestedCN)
+	preferredRootSelf := fixture.regularBundle[2]
+	globalRoot := fixture.crossSignedBundle[4]
+
+	tests := []struct {
+		name                    string
+		bundle                  []*x509.Certificate
+		preferredCN             string
+		preferredChains         []v1beta1.ChainPreference
+		expectedIntermediateCNs []string
+		expectedRootCNs         []string
+		expectedAnnotation      string
+	}{
+		{
+			name:   "first_preference_wins",
+			bundle: fixture.crossSignedBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{RootCommonName: fixture.globalRoot},
+				{RootCommonName: fixture.preferredRoot},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+			expectedRootCNs:         []string{fixture.globalRoot},
+			expectedAnnotation:      "preferredChains[0]: rootCommonName=" + fixture.globalRoot,
+		},
+		{
+			name:   "fallback_to_second_preference",
+			bundle: fixture.regularBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{RootCommonName: fixture.globalRoot},
+				{RootCommonName: fixture.preferredRoot},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate"},
+			expectedRootCNs:         []string{fixture.preferredRoot},
+			expectedAnnotation:      "preferredChains[1]: rootCommonName=" + fixture.preferredRoot,
+		},
+		{
+			name:   "spki_fingerprint",
+			bundle: fixture.crossSignedBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{RootCommonName: "Missing Root"},
+				{SPKIFingerprint: fingerprint(globalRoot.RawSubjectPublicKeyInfo)},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+			expectedRootCNs:         []string{fixture.globalRoot},
+			expectedAnnotation:      "preferredChains[1]: spkiFingerprint=" + fingerprint(globalRoot.RawSubjectPublicKeyInfo),
+		},
+		{
+			name:   "spki_fingerprint_of_cross_signed_root",
+			bundle: fixture.crossSignedBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{SPKIFingerprint: fingerprint(preferredRootSelf.RawSubjectPublicKeyInfo)},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate"},
+			expectedRootCNs:         []string{fixture.preferredRoot},
+			expectedAnnotation:      "preferredChains[0]: spkiFingerprint=" + fingerprint(preferredRootSelf.RawSubjectPublicKeyInfo),
+		},
+		{
+			name:   "certificate_fingerprint_with_colons",
+			bundle: fixture.crossSignedBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{CertificateFingerprint: colonFingerprint(globalRoot.Raw)},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+			expectedRootCNs:         []string{fixture.globalRoot},
+			expectedAnnotation:      "preferredChains[0]: certificateFingerprint=" + fingerprint(globalRoot.Raw),
+		},
+		{
+			name:   "certificate_fingerprint_not_found",
+			bundle: fixture.regularBundle,
+			preferredChains: []v1beta1.ChainPreference{
+				{CertificateFingerprint: fingerprint(globalRoot.Raw)},
+			},
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate"},
+			expectedRootCNs:         []string{fixture.preferredRoot},
+			expectedAnnotation:      preferredChainDefault,
+		},
+		{
+			name:                    "legacy_preferred_chain",
+			bundle:                  fixture.crossSignedBundle,
+			preferredCN:             fixture.globalRoot,
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+			expectedRootCNs:         []string{fixture.globalRoot},
+			expectedAnnotation:      "preferredChains[0]: rootCommonName=" + fixture.globalRoot,
+		},
+		{
+			name:                    "no_preference",
+			bundle:                  fixture.crossSignedBundle,
+			expectedIntermediateCNs: []string{fixture.requestedCN, "Test Intermediate", fixture.preferredRoot},
+			expectedRootCNs:         []string{fixture.preferredRoot, fixture.globalRoot},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockCertCentralClient := &mockCertCentralClient{submitOrder: &certcentral.Order{CertificateChain: constructCertificateChain(t, tt.bundle)}}
+			provisioner := &CertCentral{
+				client:          mockCertCentralClient,
+				preferredChain:  tt.preferredCN,
+				preferredChains: newChainPreferences(v1beta1.DigicertProvisioner{PreferredChains: tt.preferredChains}),
+				recorder:        record.NewFakeRecorder(10),
+			}
+
+			cr := &certmanagerv1.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "sign-test"},
+				Spec:       certmanagerv1.CertificateRequestSpec{Request: csrPEM},
+			}
+
+			caPEM, tlsPEM, _, err := provisioner.Sign(context.Background(), cr, OrderOptions{})
+			if err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+
+			assertChainCNs(t, tlsPEM, caPEM, tt.expectedIntermediateCNs, tt.expectedRootCNs)
+			if got := cr.GetAnnotations()[AnnotationKeyPreferredChain]; got != tt.expectedAnnotation {
+				t.Fatalf("expected annotation %q, got %q", tt.expectedAnnotation, got)
+			}
+		})
+	}
+}
+
+func TestValidateChainPreferences(t *testing.T) {
+	validFingerprint := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name      string
+		spec      v1beta1.DigicertProvisioner
+		expectErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "legacy",
+			spec: v1beta1.DigicertProvisioner{PreferredChain: "Root"},
+		},
+		{
+			name: "valid",
+			spec: v1beta1.DigicertProvisioner{PreferredChains: []v1beta1.ChainPreference{
+				{RootCommonName: "Root"},
+				{SPKIFingerprint: validFingerprint},
+				{CertificateFingerprint: strings.ToUpper(validFingerprint)},
+			}},
+		},
+		{
+			name: "both_preferred_chain_fields",
+			spec: v1beta1.DigicertProvisioner{
+				PreferredChain:  "Root",
+				PreferredChains: []v1beta1.ChainPreference{{RootCommonName: "Root"}},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "empty_preference",
+			spec:      v1beta1.DigicertProvisioner{PreferredChains: []v1beta1.ChainPreference{{}}},
+			expectErr: true,
+		},
+		{
+			name: "multiple_fields_in_preference",
+			spec: v1beta1.DigicertProvisioner{PreferredChains: []v1beta1.ChainPreference{
+				{RootCommonName: "Root", SPKIFingerprint: validFingerprint},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "invalid_fingerprint",
+			spec: v1beta1.DigicertProvisioner{PreferredChains: []v1beta1.ChainPreference{
+				{CertificateFingerprint: "not-hex"},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "sha1_fingerprint",
+			spec: v1beta1.DigicertProvisioner{PreferredChains: []v1beta1.ChainPreference{
+				{SPKIFingerprint: strings.Repeat("ab", 20)},
+			}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateChainPreferences(tt.spec)
+			if tt.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultQuotaWindow    = 24 * time.Hour
+	quotaUsageGranularity = time.Hour
+
+	// QuotaLimitOrders is the limit of the number of orders.
+	QuotaLimitOrders = "orders"
+	// QuotaLimitSpend is the limit of the estimated spend.
+	QuotaLimitSpend = "spend"
+)
+
+// QuotaExceededError is returned if another order would exceed the issuance quota of the issuer.
+type QuotaExceededError struct {
+	// Namespace is set if the quota per namespace is exceeded.
+	Namespace string
+	// Limit is either QuotaLimitOrders or QuotaLimitSpend.
+	Limit  string
+	Used   string
+	Max    string
+	Window time.Duration
+	// RetryAt is the time enough of the usage left the window for another order.
+	RetryAt time.Time
+}
+
+func (e *QuotaExceededError) Error() string {
+	scope := "issuer"
+	if e.Namespace != "" {
+		scope = "namespace " + e.Namespace
+	}
+	return fmt.Sprintf("%s quota of %s exceeded: %s of %s used within %s, retrying at %s",
+		e.Limit, scope, e.Used, e.Max, e.Window, e.RetryAt.UTC().Format(time.RFC3339))
+}
+
+// quota is the parsed issuance quota. Negative limits are unlimited.
+type quota struct {
+	window                time.Duration
+	maxOrders             float64
+	maxOrdersPerNamespace float64
+	maxSpend              float64
+	maxSpendPerNamespace  float64
+	prices                map[string]float64
+}
+
+// newQuota returns the issuance quota or nil if not configured.
+func newQuota(spec *v1beta1.IssuanceQuota) (*quota, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if err := ValidateQuota(spec); err != nil {
+		return nil, err
+	}
+
+	q := &quota{
+		window:                defaultQuotaWindow,
+		maxOrders:             -1,
+		maxOrdersPerNamespace: -1,
+		maxSpend:              -1,
+		maxSpendPerNamespace:  -1,
+		prices:                make(map[string]float64, len(spec.Prices)),
+	}
+	if spec.Window != nil {
+		q.window = spec.Window.Duration
+	}
+	if spec.MaxOrders != nil {
+		q.maxOrders = float64(*spec.MaxOrders)
+	}
+	if spec.MaxOrdersPerNamespace != nil {
+		q.maxOrdersPerNamespace = float64(*spec.MaxOrdersPerNamespace)
+	}
+	if spec.MaxSpend != "" {
+		q.maxSpend, _ = strconv.ParseFloat(spec.MaxSpend, 64)
+	}
+	if spec.MaxSpendPerNamespace != "" {
+		q.maxSpendPerNamespace, _ = strconv.ParseFloat(spec.MaxSpendPerNamespace, 64)
+	}
+	for name, price := range spec.Prices {
+		orderType, _ := mapToOrderType(name)
+		q.prices[orderType.String()], _ = strconv.ParseFloat(price, 64)
+	}
+	return q, nil
+}
+
+// CheckQuota returns a QuotaExceededError if another order for a CertificateRequest from the namespace would exceed
+// the issuance quota of the issuer given its current usage.
+func (c *CertCentral) CheckQuota(usage []v1beta1.QuotaUsage, namespace string, now time.Time) error {
+	q := c.quota
+	if q == nil {
+		return nil
+	}
+
+	usage = q.inWindow(usage, now)
+	var namespaceUsage []v1beta1.QuotaUsage
+	for _, u := range usage {
+		if u.Namespace == namespace {
+			namespaceUsage = append(namespaceUsage, u)
+		}
+	}
+
+	price := q.prices[c.orderType.String()]
+	if err := q.check(usage, QuotaLimitOrders, orderCount, 1, q.maxOrders); err != nil {
+		return err
+	}
+	if err := q.check(namespaceUsage, QuotaLimitOrders, orderCount, 1, q.maxOrdersPerNamespace); err != nil {
+		err.Namespace = namespace
+		return err
+	}
+	if err := q.check(usage, QuotaLimitSpend, spend, price, q.maxSpend); err != nil {
+		return err
+	}
+	if err := q.check(namespaceUsage, QuotaLimitSpend, spend, price, q.maxSpendPerNamespace); err != nil {
+		err.Namespace = namespace
+		return err
+	}
+	return nil
+}
+
+// RecordQuotaUsage returns the usage including the order and without the usage outside of the quota window.
+// The price returned by CertCentral takes precedence over the configured price. Returns false if no quota is configured.
+func (c *CertCentral) RecordQuotaUsage(usage []v1beta1.QuotaUsage, namespace string, order *Order, now time.Time) ([]v1beta1.QuotaUsage, bool) {
+	q := c.quota
+	if q == nil {
+		return usage, false
+	}
+
+	price := q.prices[c.orderType.String()]
+	if order != nil && order.Price > 0 {
+		price = order.Price
+	}
+
+	usage = q.inWindow(usage, now)
+	start := metav1.NewTime(now.Truncate(quotaUsageGranularity))
+	for idx := range usage {
+		if usage[idx].Namespace == namespace && usage[idx].Start.Equal(&start) {
+			usage[idx].Orders++
+			usage[idx].Spend = formatSpend(spend(usage[idx]) + price)
+			return usage, true
+		}
+	}
+	return append(usage, v1beta1.QuotaUsage{
+		Start:     start,
+		Namespace: namespace,
+		Orders:    1,
+		Spend:     formatSpend(price),
+	}), true
+}
+
+// inWindow returns a copy of the usage overlapping the quota window sorted by time.
+func (q *quota) inWindow(usage []v1beta1.QuotaUsage, now time.Time) []v1beta1.QuotaUsage {
+	since := now.Add(-q.window)
+	res := make([]v1beta1.QuotaUsage, 0, len(usage))
+	for _, u := range usage {
+		if u.Start.Add(quotaUsageGranularity).After(since) {
+			res = append(res, u)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Start.Before(&res[j].Start)
+	})
+	return res
+}
+
+// check returns an error if the value of another order does not fit the limit.
+// The usage must be sorted by time to determine when enough of it left the window.
+func (q *quota) check(usage []v1beta1.QuotaUsage, limitName string, value func(v1beta1.QuotaUsage) float64, next, limit float64) *QuotaExceededError {
+	if limit < 0 {
+		return nil
+	}
+
+	fits := func(used float64) bool {
+		if next == 0 {
+			return used < limit
+		}
+		return used+next <= limit
+	}
+
+	var used float64
+	for _, u := range usage {
+		used += value(u)
+	}
+	if fits(used) {
+		return nil
+	}
+
+	err := &QuotaExceededError{
+		Limit:  limitName,
+		Used:   formatQuotaValue(limitName, used),
+		Max:    formatQuotaValue(limitName, limit),
+		Window: q.window,
+	}
+	remaining := used
+	for _, u := range usage {
+		remaining -= value(u)
+		err.RetryAt = u.Start.Add(quotaUsageGranularity + q.window)
+		if fits(remaining) {
+			break
+		}
+	}
+	return err
+}
+
+func orderCount(u v1beta1.QuotaUsage) float64 {
+	return float64(u.Orders)
+}
+
+func spend(u v1beta1.QuotaUsage) float64 {
+	v, err := strconv.ParseFloat(u.Spend, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func formatSpend(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatQuotaValue(limitName string, v float64) string {
+	if limitName == QuotaLimitOrders {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// ValidateQuota validates the limits and prices of the issuance quota.
+func ValidateQuota(spec *v1beta1.IssuanceQuota) error {
+	if spec == nil {
+		return nil
+	}
+
+	var errs error
+	if spec.Window != nil && spec.Window.Duration < quotaUsageGranularity {
+		errs = multierror.Append(errs, fmt.Errorf("spec.quota.window must be at least %s", quotaUsageGranularity))
+	}
+	if spec.MaxOrders != nil && *spec.MaxOrders < 0 {
+		errs = multierror.Append(errs, errors.New("spec.quota.maxOrders must not be negative"))
+	}
+	if spec.MaxOrdersPerNamespace != nil && *spec.MaxOrdersPerNamespace < 0 {
+		errs = multierror.Append(errs, errors.New("spec.quota.maxOrdersPerNamespace must not be negative"))
+	}
+	if !isDecimal(spec.MaxSpend) {
+		errs = multierror.Append(errs, fmt.Errorf("spec.quota.maxSpend %q is not a decimal", spec.MaxSpend))
+	}
+	if !isDecimal(spec.MaxSpendPerNamespace) {
+		errs = multierror.Append(errs, fmt.Errorf("spec.quota.maxSpendPerNamespace %q is not a decimal", spec.MaxSpendPerNamespace))
+	}
+	for orderType, price := range spec.Prices {
+		if _, ok := mapToOrderType(orderType); !ok {
+			errs = multierror.Append(errs, fmt.Errorf("spec.quota.prices: order type %q is not supported", orderType))
+		}
+		if price == "" || !isDecimal(price) {
+			errs = multierror.Append(errs, fmt.Errorf("spec.quota.prices[%s] %q is not a decimal", orderType, price))
+		}
+	}
+	return errs
+}
+
+// isDecimal returns true if the value is empty or a non-negative decimal.
+func isDecimal(v string) bool {
+	if v == "" {
+		return true
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	return err == nil && f >= 0
+}
@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	certcentral "github.com/sapcc/go-certcentral"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestCertCentralCheckQuota(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+	hour := func(h int) metav1.Time {
+		return metav1.NewTime(now.Truncate(time.Hour).Add(time.Duration(h) * time.Hour))
+	}
+	usage := []v1beta1.QuotaUsage{
+		{Start: hour(-30), Namespace: "team-a", Orders: 5, Spend: "500.00"},
+		{Start: hour(-3), Namespace: "team-a", Orders: 2, Spend: "200.00"},
+		{Start: hour(-1), Namespace: "team-b", Orders: 1, Spend: "100.00"},
+		{Start: hour(0), Namespace: "team-a", Orders: 1, Spend: "100.00"},
+	}
+
+	tests := []struct {
+		name            string
+		quota           v1beta1.IssuanceQuota
+		namespace       string
+		expectLimit     string
+		expectNamespace string
+		expectRetryAt   time.Time
+	}{
+		{
+			name:      "within limits",
+			quota:     v1beta1.IssuanceQuota{MaxOrders: ptr.To(5), MaxSpend: "500"},
+			namespace: "team-a",
+		},
+		{
+			name:          "orders of issuer",
+			quota:         v1beta1.IssuanceQuota{MaxOrders: ptr.To(4)},
+			namespace:     "team-b",
+			expectLimit:   QuotaLimitOrders,
+			expectRetryAt: hour(-2).Add(24 * time.Hour),
+		},
+		{
+			name:            "orders of namespace",
+			quota:           v1beta1.IssuanceQuota{MaxOrdersPerNamespace: ptr.To(3)},
+			namespace:       "team-a",
+			expectLimit:     QuotaLimitOrders,
+			expectNamespace: "team-a",
+			expectRetryAt:   hour(-2).Add(24 * time.Hour),
+		},
+		{
+			name:      "orders of other namespace",
+			quota:     v1beta1.IssuanceQuota{MaxOrdersPerNamespace: ptr.To(3)},
+			namespace: "team-b",
+		},
+		{
+			name:          "spend with estimated price",
+			quota:         v1beta1.IssuanceQuota{MaxSpend: "450", Prices: map[string]string{"ssl_plus": "100"}},
+			namespace:     "team-b",
+			expectLimit:   QuotaLimitSpend,
+			expectRetryAt: hour(-2).Add(24 * time.Hour),
+		},
+		{
+			name:      "spend without price",
+			quota:     v1beta1.IssuanceQuota{MaxSpend: "450"},
+			namespace: "team-b",
+		},
+		{
+			name:            "spend of namespace in shorter window",
+			quota:           v1beta1.IssuanceQuota{Window: &metav1.Duration{Duration: 2 * time.Hour}, MaxSpendPerNamespace: "100"},
+			namespace:       "team-a",
+			expectLimit:     QuotaLimitSpend,
+			expectNamespace: "team-a",
+			expectRetryAt:   hour(1).Add(2 * time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := newQuota(&tt.quota)
+			if err != nil {
+				t.Fatalf("newQuota returned error: %v", err)
+			}
+			provisioner := &CertCentral{quota: q, orderType: certcentral.OrderTypes.SSLPlus}
+
+			err = provisioner.CheckQuota(usage, tt.namespace, now)
+			if tt.expectLimit == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var exceeded *QuotaExceededError
+			if !errors.As(err, &exceeded) {
+				t.Fatalf("expected QuotaExceededError, got %v", err)
+			}
+			if exceeded.Limit != tt.expectLimit || exceeded.Namespace != tt.expectNamespace {
+				t.Fatalf("unexpected limit %q of namespace %q exceeded", exceeded.Limit, exceeded.Namespace)
+			}
+			if !exceeded.RetryAt.Equal(tt.expectRetryAt) {
+				t.Fatalf("unexpected retry time, got=%s expected=%s", exceeded.RetryAt, tt.expectRetryAt)
+			}
+		})
+	}
+}
+
+func TestCertCentralRecordQuotaUsage(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+	q, err := newQuota(&v1beta1.IssuanceQuota{Prices: map[string]string{"SSL_PLUS": "99.5"}})
+	if err != nil {
+		t.Fatalf("newQuota returned error: %v", err)
+	}
+	provisioner := &CertCentral{quota: q, orderType: certcentral.OrderTypes.SSLPlus}
+
+	usage := []v1beta1.QuotaUsage{
+		{Start: metav1.NewTime(now.Add(-48 * time.Hour).Truncate(time.Hour)), Namespace: "team-a", Orders: 3},
+	}
+	usage, changed := provisioner.RecordQuotaUsage(usage, "team-a", &Order{}, now)
+	if !changed {
+		t.Fatalf("expected usage to change")
+	}
+	usage, _ = provisioner.RecordQuotaUsage(usage, "team-a", &Order{Price: 120}, now.Add(time.Minute))
+
+	if len(usage) != 1 {
+		t.Fatalf("expected usage outside of the window to be dropped, got %v", usage)
+	}
+	if usage[0].Orders != 2 || usage[0].Spend != "219.50" {
+		t.Fatalf("unexpected usage %+v", usage[0])
+	}
+
+	if _, changed := (&CertCentral{}).RecordQuotaUsage(nil, "team-a", &Order{}, now); changed {
+		t.Fatalf("expected no usage without quota")
+	}
+}
+
+func TestValidateQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		quota     *v1beta1.IssuanceQuota
+		expectErr bool
+	}{
+		{name: "not set"},
+		{name: "valid", quota: &v1beta1.IssuanceQuota{MaxOrders: ptr.To(10), MaxSpend: "1000.50", Prices: map[string]string{"ssl_plus": "100"}}},
+		{name: "short window", quota: &v1beta1.IssuanceQuota{Window: &metav1.Duration{Duration: time.Minute}}, expectErr: true},
+		{name: "invalid spend", quota: &v1beta1.IssuanceQuota{MaxSpend: "-1"}, expectErr: true},
+		{name: "unknown order type", quota: &v1beta1.IssuanceQuota{Prices: map[string]string{"free": "0"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateQuota(tt.quota); (err != nil) != tt.expectErr {
+				t.Fatalf("unexpected result, expected error=%t, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
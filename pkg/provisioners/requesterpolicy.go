@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+// RequesterDeniedError is returned if the requester policy of the issuer does not allow the creator of a
+// CertificateRequest to request the certificate.
+type RequesterDeniedError struct {
+	Username string
+	Name     string
+}
+
+func (e *RequesterDeniedError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("requester %q is not allowed to request certificates from this issuer", e.Username)
+	}
+	return fmt.Sprintf("requester %q is not allowed to request certificates for %q", e.Username, e.Name)
+}
+
+// AuthorizeRequester returns a RequesterDeniedError if the creator of the CertificateRequest is not allowed to
+// request a certificate for all names of the certificate request by the requester policy of the issuer.
+// Other errors are returned if the certificate request can't be decoded.
+func (c *CertCentral) AuthorizeRequester(cr *certmanagerv1.CertificateRequest) error {
+	if c.requesterPolicy == nil {
+		return nil
+	}
+
+	var subjects []v1beta1.RequesterSubject
+	for _, subject := range c.requesterPolicy.Subjects {
+		if matchesRequester(subject, cr.Spec.Username, cr.Spec.Groups) {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) == 0 {
+		return &RequesterDeniedError{Username: cr.Spec.Username}
+	}
+
+	certReq, err := decodeCertificateRequest(cr.Spec.Request)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate request: %w", err)
+	}
+	names := certReq.DNSNames
+	if cn := certReq.Subject.CommonName; cn != "" {
+		names = append([]string{cn}, names...)
+	}
+	for _, ip := range certReq.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	for _, name := range names {
+		allowed := slices.ContainsFunc(subjects, func(subject v1beta1.RequesterSubject) bool {
+			return len(subject.Domains) == 0 || slices.ContainsFunc(subject.Domains, func(domain string) bool {
+				return matchesDomain(domain, name)
+			})
+		})
+		if !allowed {
+			return &RequesterDeniedError{Username: cr.Spec.Username, Name: name}
+		}
+	}
+	return nil
+}
+
+func matchesRequester(subject v1beta1.RequesterSubject, username string, groups []string) bool {
+	switch subject.Kind {
+	case rbacv1.UserKind:
+		return subject.Name == username
+	case rbacv1.GroupKind:
+		return slices.Contains(groups, subject.Name)
+	case rbacv1.ServiceAccountKind:
+		return username == fmt.Sprintf("system:serviceaccount:%s:%s", subject.Namespace, subject.Name)
+	default:
+		return false
+	}
+}
+
+// matchesDomain returns true if the name equals the domain or, if the domain starts with a wildcard,
+// the name is a subdomain of it.
+func matchesDomain(domain, name string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if domain == name {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(domain, "*"); ok {
+		return strings.HasSuffix(name, suffix) && len(name) > len(suffix)
+	}
+	return false
+}
+
+// ValidateRequesterPolicy validates the subjects of the requester policy.
+func ValidateRequesterPolicy(policy *v1beta1.RequesterPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	var errs error
+	for idx, subject := range policy.Subjects {
+		switch subject.Kind {
+		case rbacv1.UserKind, rbacv1.GroupKind:
+		case rbacv1.ServiceAccountKind:
+			if subject.Namespace == "" {
+				errs = multierror.Append(errs, fmt.Errorf("spec.requesterPolicy.subjects[%d].namespace missing", idx))
+			}
+		default:
+			errs = multierror.Append(errs, fmt.Errorf("spec.requesterPolicy.subjects[%d].kind %q is not supported", idx, subject.Kind))
+		}
+		if subject.Name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("spec.requesterPolicy.subjects[%d].name missing", idx))
+		}
+		for domainIdx, domain := range subject.Domains {
+			if domain == "" || domain == "*" || strings.Contains(strings.TrimPrefix(domain, "*."), "*") {
+				errs = multierror.Append(errs, fmt.Errorf("spec.requesterPolicy.subjects[%d].domains[%d] %q is invalid", idx, domainIdx, domain))
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"errors"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+func TestCertCentralAuthorizeRequester(t *testing.T) {
+	policy := &v1beta1.RequesterPolicy{
+		Subjects: []v1beta1.RequesterSubject{
+			{Kind: "ServiceAccount", Namespace: "cert-manager", Name: "cert-manager"},
+			{Kind: "User", Name: "alice", Domains: []string{"*.team-a.example.com"}},
+			{Kind: "Group", Name: "team-b", Domains: []string{"team-b.example.com"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		policy       *v1beta1.RequesterPolicy
+		username     string
+		groups       []string
+		commonName   string
+		expectDenied bool
+	}{
+		{
+			name:       "no policy",
+			username:   "mallory",
+			commonName: "example.com",
+		},
+		{
+			name:       "service account without domain restriction",
+			policy:     policy,
+			username:   "system:serviceaccount:cert-manager:cert-manager",
+			commonName: "example.com",
+		},
+		{
+			name:         "service account from other namespace",
+			policy:       policy,
+			username:     "system:serviceaccount:default:cert-manager",
+			commonName:   "example.com",
+			expectDenied: true,
+		},
+		{
+			name:       "user within wildcard domain",
+			policy:     policy,
+			username:   "alice",
+			commonName: "api.team-a.example.com",
+		},
+		{
+			name:         "user for wildcard domain itself",
+			policy:       policy,
+			username:     "alice",
+			commonName:   "team-a.example.com",
+			expectDenied: true,
+		},
+		{
+			name:       "group member for exact domain",
+			policy:     policy,
+			username:   "bob",
+			groups:     []string{"system:authenticated", "team-b"},
+			commonName: "team-b.example.com",
+		},
+		{
+			name:         "group member for other domain",
+			policy:       policy,
+			username:     "bob",
+			groups:       []string{"team-b"},
+			commonName:   "api.team-a.example.com",
+			expectDenied: true,
+		},
+		{
+			name:         "unlisted user",
+			policy:       policy,
+			username:     "mallory",
+			groups:       []string{"system:authenticated"},
+			commonName:   "team-b.example.com",
+			expectDenied: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provisioner := &CertCentral{requesterPolicy: tt.policy}
+			cr := &certmanagerv1.CertificateRequest{
+				Spec: certmanagerv1.CertificateRequestSpec{
+					Request:  createCSR(t, tt.commonName),
+					Username: tt.username,
+					Groups:   tt.groups,
+				},
+			}
+
+			err := provisioner.AuthorizeRequester(cr)
+			var denied *RequesterDeniedError
+			if tt.expectDenied != errors.As(err, &denied) {
+				t.Fatalf("unexpected result, expected denied=%t, got err=%v", tt.expectDenied, err)
+			}
+			if !tt.expectDenied && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Invalid certificate requests of allowed requesters fail instead of being denied.
+	provisioner := &CertCentral{requesterPolicy: policy}
+	cr := &certmanagerv1.CertificateRequest{
+		Spec: certmanagerv1.CertificateRequestSpec{
+			Request:  []byte("invalid"),
+			Username: "alice",
+		},
+	}
+	err := provisioner.AuthorizeRequester(cr)
+	var denied *RequesterDeniedError
+	if err == nil || errors.As(err, &denied) {
+		t.Fatalf("expected decoding error, got %v", err)
+	}
+}
+
+func TestValidateRequesterPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *v1beta1.RequesterPolicy
+		expectErr bool
+	}{
+		{name: "not set"},
+		{
+			name: "valid",
+			policy: &v1beta1.RequesterPolicy{Subjects: []v1beta1.RequesterSubject{
+				{Kind: "User", Name: "alice", Domains: []string{"*.example.com", "example.com"}},
+				{Kind: "ServiceAccount", Namespace: "cert-manager", Name: "cert-manager"},
+			}},
+		},
+		{
+			name:      "service account without namespace",
+			policy:    &v1beta1.RequesterPolicy{Subjects: []v1beta1.RequesterSubject{{Kind: "ServiceAccount", Name: "cert-manager"}}},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported kind",
+			policy:    &v1beta1.RequesterPolicy{Subjects: []v1beta1.RequesterSubject{{Kind: "Role", Name: "admin"}}},
+			expectErr: true,
+		},
+		{
+			name:      "wildcard in the middle",
+			policy:    &v1beta1.RequesterPolicy{Subjects: []v1beta1.RequesterSubject{{Kind: "User", Name: "alice", Domains: []string{"api.*.example.com"}}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateRequesterPolicy(tt.policy); (err != nil) != tt.expectErr {
+				t.Fatalf("unexpected result, expected error=%t, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	// maxRevocationResponseSize limits the size of OCSP responses and CRLs.
+	maxRevocationResponseSize = 10 << 20
+
+	revocationFetchTimeout = 30 * time.Second
+
+	// revocationClockSkew is the tolerated difference between the clocks of the OCSP responder and the issuer.
+	revocationClockSkew = 5 * time.Minute
+)
+
+// RevocationStatus is the revocation status of a certificate.
+type RevocationStatus string
+
+const (
+	RevocationStatusGood    RevocationStatus = "Good"
+	RevocationStatusRevoked RevocationStatus = "Revoked"
+	RevocationStatusUnknown RevocationStatus = "Unknown"
+)
+
+// RevocationResult is the result of a revocation check.
+type RevocationResult struct {
+	Status RevocationStatus
+	// Source is the OCSP responder or CRL distribution point the status was retrieved from.
+	Source    string
+	RevokedAt time.Time
+	// Reason is the revocation reason code as defined in RFC 5280.
+	Reason int
+}
+
+// RevocationChecker retrieves the revocation status of certificates via OCSP and falls back to CRLs.
+type RevocationChecker struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewRevocationChecker returns a new RevocationChecker. A default client is used if nil.
+func NewRevocationChecker(httpClient *http.Client) *RevocationChecker {
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout:   revocationFetchTimeout,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		}
+	}
+	return &RevocationChecker{client: httpClient, now: time.Now}
+}
+
+// Check returns the revocation status of the leaf of the chain. The chain must contain the issuer of the leaf.
+// OCSP responders are queried first. CRLs are only fetched if no OCSP responder returned a definite status.
+func (rc *RevocationChecker) Check(ctx context.Context, chain []*x509.Certificate) (RevocationResult, error) {
+	leaf := findLeaf(chain)
+	if leaf == nil {
+		return RevocationResult{}, errors.New("leaf certificate not found in chain")
+	}
+	issuer := findIssuer(leaf, chain, []*x509.Certificate{leaf})
+	if issuer == nil {
+		return RevocationResult{}, fmt.Errorf("issuer of certificate %q not found in chain", leaf.Subject.CommonName)
+	}
+	if len(leaf.OCSPServer) == 0 && len(leaf.CRLDistributionPoints) == 0 {
+		return RevocationResult{}, fmt.Errorf("certificate %q has neither OCSP responder nor CRL distribution point", leaf.Subject.CommonName)
+	}
+
+	var errs []string
+	for _, url := range leaf.OCSPServer {
+		result, err := rc.checkOCSP(ctx, url, leaf, issuer)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if result.Status != RevocationStatusUnknown {
+			return result, nil
+		}
+	}
+
+	for _, url := range leaf.CRLDistributionPoints {
+		result, err := rc.checkCRL(ctx, url, leaf, issuer)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		return result, nil
+	}
+
+	if len(errs) > 0 {
+		return RevocationResult{Status: RevocationStatusUnknown}, errors.New(strings.Join(errs, "; "))
+	}
+	return RevocationResult{Status: RevocationStatusUnknown}, nil
+}
+
+func (rc *RevocationChecker) checkOCSP(ctx context.Context, url string, leaf, issuer *x509.Certificate) (RevocationResult, error) {
+	ocspReq, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return RevocationResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(ocspReq))
+	if err != nil {
+		return RevocationResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	req.Header.Set("Accept", "application/ocsp-response")
+
+	data, err := rc.fetch(req)
+	if err != nil {
+		return RevocationResult{}, err
+	}
+	res, err := ocsp.ParseResponseForCert(data, leaf, issuer)
+	if err != nil {
+		return RevocationResult{}, fmt.Errorf("invalid OCSP response from %s: %w", url, err)
+	}
+	// Like stale CRLs, stale or replayed responses may lack recent revocations.
+	now := rc.now()
+	if res.ThisUpdate.After(now.Add(revocationClockSkew)) {
+		return RevocationResult{}, fmt.Errorf("OCSP response from %s is not valid before %s", url, res.ThisUpdate.UTC().Format(time.RFC3339))
+	}
+	if !res.NextUpdate.IsZero() && now.After(res.NextUpdate) {
+		return RevocationResult{}, fmt.Errorf("OCSP response from %s is stale since %s", url, res.NextUpdate.UTC().Format(time.RFC3339))
+	}
+
+	result := RevocationResult{Status: RevocationStatusUnknown, Source: url}
+	switch res.Status {
+	case ocsp.Good:
+		result.Status = RevocationStatusGood
+	case ocsp.Revoked:
+		result.Status = RevocationStatusRevoked
+		result.RevokedAt = res.RevokedAt
+		result.Reason = res.RevocationReason
+	}
+	return result, nil
+}
+
+func (rc *RevocationChecker) checkCRL(ctx context.Context, url string, leaf, issuer *x509.Certificate) (RevocationResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return RevocationResult{}, err
+	}
+	data, err := rc.fetch(req)
+	if err != nil {
+		return RevocationResult{}, err
+	}
+
+	// CRLs are usually DER encoded, but some CAs serve PEM.
+	if block, _ := pem.Decode(data); block != nil && block.Type == "X509 CRL" {
+		data = block.Bytes
+	}
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return RevocationResult{}, fmt.Errorf("invalid CRL from %s: %w", url, err)
+	}
+	if err := crl.CheckSignatureFrom(issuer); err != nil {
+		return RevocationResult{}, fmt.Errorf("CRL from %s not signed by %q: %w", url, issuer.Subject.CommonName, err)
+	}
+	// A CRL past its next update may lack recent revocations.
+	if !crl.NextUpdate.IsZero() && rc.now().After(crl.NextUpdate) {
+		return RevocationResult{}, fmt.Errorf("CRL from %s is stale since %s", url, crl.NextUpdate.UTC().Format(time.RFC3339))
+	}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(leaf.SerialNumber) == 0 {
+			return RevocationResult{
+				Status:    RevocationStatusRevoked,
+				Source:    url,
+				RevokedAt: entry.RevocationTime,
+				Reason:    entry.ReasonCode,
+			}, nil
+		}
+	}
+	return RevocationResult{Status: RevocationStatusGood, Source: url}, nil
+}
+
+func (rc *RevocationChecker) fetch(req *http.Request) ([]byte, error) {
+	res, err := rc.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s failed with status %s", req.URL, res.Status)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxRevocationResponseSize))
+}
@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// revocationResponder is a local OCSP responder and CRL distribution point for the verify fixture.
+type revocationResponder struct {
+	fixture    verifyFixture
+	now        time.Time
+	ocspStatus int
+	ocspFails  bool
+	ocspStale  bool
+	crlStale   bool
+	revoked    []*x509.Certificate
+}
+
+func (rr *revocationResponder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/ocsp":
+		if rr.ocspFails {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		nextUpdate := rr.now.Add(time.Hour)
+		if rr.ocspStale {
+			nextUpdate = rr.now.Add(-time.Minute)
+		}
+		res, err := ocsp.CreateResponse(rr.fixture.intermediate, rr.fixture.intermediate, ocsp.Response{
+			Status:           rr.ocspStatus,
+			SerialNumber:     req.SerialNumber,
+			ThisUpdate:       rr.now.Add(-time.Hour),
+			NextUpdate:       nextUpdate,
+			RevokedAt:        rr.now.Add(-time.Minute),
+			RevocationReason: ocsp.KeyCompromise,
+		}, rr.fixture.intermediateKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(res)
+	case "/crl":
+		entries := make([]x509.RevocationListEntry, 0, len(rr.revoked))
+		for _, cert := range rr.revoked {
+			entries = append(entries, x509.RevocationListEntry{
+				SerialNumber:   cert.SerialNumber,
+				RevocationTime: rr.now.Add(-time.Minute),
+				ReasonCode:     ocsp.Superseded,
+			})
+		}
+		nextUpdate := rr.now.Add(time.Hour)
+		if rr.crlStale {
+			nextUpdate = rr.now.Add(-time.Minute)
+		}
+		crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:                    big.NewInt(1),
+			ThisUpdate:                rr.now.Add(-time.Hour),
+			NextUpdate:                nextUpdate,
+			RevokedCertificateEntries: entries,
+		}, rr.fixture.intermediate, rr.fixture.intermediateKey)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(crl)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestRevocationCheckerCheck(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		ocspStatus     int
+		ocspFails      bool
+		ocspStale      bool
+		crlRevoked     bool
+		crlStale       bool
+		noEndpoints    bool
+		expectedStatus RevocationStatus
+		expectedSource string
+		expectErr      bool
+	}{
+		{
+			name:           "good via OCSP",
+			ocspStatus:     ocsp.Good,
+			expectedStatus: RevocationStatusGood,
+			expectedSource: "/ocsp",
+		},
+		{
+			name:           "revoked via OCSP",
+			ocspStatus:     ocsp.Revoked,
+			expectedStatus: RevocationStatusRevoked,
+			expectedSource: "/ocsp",
+		},
+		{
+			name:           "unknown via OCSP falls back to CRL",
+			ocspStatus:     ocsp.Unknown,
+			crlRevoked:     true,
+			expectedStatus: RevocationStatusRevoked,
+			expectedSource: "/crl",
+		},
+		{
+			name:           "OCSP unavailable falls back to CRL",
+			ocspFails:      true,
+			expectedStatus: RevocationStatusGood,
+			expectedSource: "/crl",
+		},
+		{
+			name:           "stale OCSP response falls back to CRL",
+			ocspStatus:     ocsp.Good,
+			ocspStale:      true,
+			crlRevoked:     true,
+			expectedStatus: RevocationStatusRevoked,
+			expectedSource: "/crl",
+		},
+		{
+			name:       "stale OCSP response and stale CRL",
+			ocspStatus: ocsp.Good,
+			ocspStale:  true,
+			crlStale:   true,
+			expectErr:  true,
+		},
+		{
+			name:       "OCSP unavailable and stale CRL",
+			ocspFails:  true,
+			crlRevoked: true,
+			crlStale:   true,
+			expectErr:  true,
+		},
+		{
+			name:        "no revocation endpoints",
+			noEndpoints: true,
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			responder := &revocationResponder{fixture: fixture, now: now, ocspStatus: tt.ocspStatus, ocspFails: tt.ocspFails, ocspStale: tt.ocspStale, crlStale: tt.crlStale}
+			server := httptest.NewServer(responder)
+			defer server.Close()
+
+			leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(tmpl *x509.Certificate) {
+				if !tt.noEndpoints {
+					tmpl.OCSPServer = []string{server.URL + "/ocsp"}
+					tmpl.CRLDistributionPoints = []string{server.URL + "/crl"}
+				}
+			})
+			if tt.crlRevoked {
+				responder.revoked = []*x509.Certificate{leaf}
+			}
+
+			checker := NewRevocationChecker(server.Client())
+			checker.now = func() time.Time { return now }
+			result, err := checker.Check(context.Background(), []*x509.Certificate{leaf, fixture.intermediate})
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Fatalf("expected status %s, got %s", tt.expectedStatus, result.Status)
+			}
+			if result.Source != server.URL+tt.expectedSource {
+				t.Fatalf("expected source %s, got %s", server.URL+tt.expectedSource, result.Source)
+			}
+			if result.Status == RevocationStatusRevoked && result.RevokedAt.IsZero() {
+				t.Fatalf("expected revocation time to be set")
+			}
+		})
+	}
+}
+
+func TestRevocationCheckerCheckMissingIssuer(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(tmpl *x509.Certificate) {
+		tmpl.OCSPServer = []string{"http://127.0.0.1:1/ocsp"}
+	})
+
+	if _, err := NewRevocationChecker(nil).Check(context.Background(), []*x509.Certificate{leaf}); err == nil {
+		t.Fatalf("expected error if the issuer is missing")
+	}
+}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/robfig/cron/v3"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+)
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Suspension explains why the provisioner does not place new orders.
+type Suspension struct {
+	Reason  v1beta1.ConditionReason
+	Message string
+	// Until is the start of the next issuance window. Zero if the issuer is suspended.
+	Until time.Time
+}
+
+type issuanceWindow struct {
+	schedule cron.Schedule
+	duration time.Duration
+	location *time.Location
+}
+
+// contains returns true if the window started within its duration before the given time.
+func (w issuanceWindow) contains(t time.Time) bool {
+	start := w.schedule.Next(t.In(w.location).Add(-w.duration))
+	return !start.After(t)
+}
+
+func newIssuanceWindows(specs []v1beta1.IssuanceWindow) ([]issuanceWindow, error) {
+	var (
+		windows []issuanceWindow
+		errs    error
+	)
+	for idx, spec := range specs {
+		schedule, err := cronParser.Parse(spec.Schedule)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("spec.issuanceWindows[%d].schedule is invalid: %w", idx, err))
+		}
+		if spec.Duration.Duration <= 0 {
+			errs = multierror.Append(errs, fmt.Errorf("spec.issuanceWindows[%d].duration must be positive", idx))
+		}
+		location := time.UTC
+		if spec.TimeZone != "" {
+			if location, err = time.LoadLocation(spec.TimeZone); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("spec.issuanceWindows[%d].timeZone is invalid: %w", idx, err))
+			}
+		}
+		windows = append(windows, issuanceWindow{schedule: schedule, duration: spec.Duration.Duration, location: location})
+	}
+	return windows, errs
+}
+
+// ValidateIssuanceWindows validates the schedules, durations and time zones of the issuance windows.
+func ValidateIssuanceWindows(specs []v1beta1.IssuanceWindow) error {
+	_, err := newIssuanceWindows(specs)
+	return err
+}
+
+// Suspension returns why no new orders are placed at the given time or nil if orders can be placed.
+func (c *CertCentral) Suspension(now time.Time) *Suspension {
+	if c.suspend {
+		return &Suspension{Reason: v1beta1.ConditionReasonSuspended, Message: "Issuer is suspended"}
+	}
+	if len(c.issuanceWindows) == 0 {
+		return nil
+	}
+
+	var next time.Time
+	for _, w := range c.issuanceWindows {
+		if w.contains(now) {
+			return nil
+		}
+		if start := w.schedule.Next(now.In(w.location)); next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return &Suspension{
+		Reason:  v1beta1.ConditionReasonOutsideIssuanceWindow,
+		Message: fmt.Sprintf("Outside of issuance windows until %s", next.UTC().Format(time.RFC3339)),
+		Until:   next,
+	}
+}
+
+// NextIssuanceWindowChange returns the next time an issuance window starts or ends.
+// Returns the zero time if no issuance windows are configured.
+func (c *CertCentral) NextIssuanceWindowChange(now time.Time) time.Time {
+	var next time.Time
+	for _, w := range c.issuanceWindows {
+		change := w.schedule.Next(now.In(w.location))
+		if w.contains(now) {
+			change = w.schedule.Next(now.In(w.location).Add(-w.duration)).Add(w.duration)
+		}
+		if next.IsZero() || change.Before(next) {
+			next = change
+		}
+	}
+	return next
+}
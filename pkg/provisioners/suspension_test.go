@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertCentralSuspension(t *testing.T) {
+	weekdays := []v1beta1.IssuanceWindow{
+		{Schedule: "0 8 * * 1-5", Duration: metav1.Duration{Duration: 10 * time.Hour}, TimeZone: "Europe/Berlin"},
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		suspend      bool
+		windows      []v1beta1.IssuanceWindow
+		now          time.Time
+		expectReason v1beta1.ConditionReason
+		expectUntil  time.Time
+		expectChange time.Time
+	}{
+		{
+			name: "no restrictions",
+			now:  time.Date(2025, 6, 2, 12, 0, 0, 0, berlin),
+		},
+		{
+			name:         "suspended",
+			suspend:      true,
+			windows:      weekdays,
+			now:          time.Date(2025, 6, 2, 12, 0, 0, 0, berlin),
+			expectReason: v1beta1.ConditionReasonSuspended,
+			expectChange: time.Date(2025, 6, 2, 18, 0, 0, 0, berlin),
+		},
+		{
+			name:         "within window",
+			windows:      weekdays,
+			now:          time.Date(2025, 6, 2, 12, 0, 0, 0, berlin),
+			expectChange: time.Date(2025, 6, 2, 18, 0, 0, 0, berlin),
+		},
+		{
+			name:         "start of window",
+			windows:      weekdays,
+			now:          time.Date(2025, 6, 2, 8, 0, 0, 0, berlin),
+			expectChange: time.Date(2025, 6, 2, 18, 0, 0, 0, berlin),
+		},
+		{
+			name:         "end of window",
+			windows:      weekdays,
+			now:          time.Date(2025, 6, 2, 18, 0, 0, 0, berlin),
+			expectReason: v1beta1.ConditionReasonOutsideIssuanceWindow,
+			expectUntil:  time.Date(2025, 6, 3, 8, 0, 0, 0, berlin),
+			expectChange: time.Date(2025, 6, 3, 8, 0, 0, 0, berlin),
+		},
+		{
+			name:         "weekend",
+			windows:      weekdays,
+			now:          time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC),
+			expectReason: v1beta1.ConditionReasonOutsideIssuanceWindow,
+			expectUntil:  time.Date(2025, 6, 9, 8, 0, 0, 0, berlin),
+			expectChange: time.Date(2025, 6, 9, 8, 0, 0, 0, berlin),
+		},
+		{
+			name: "any matching window",
+			windows: append([]v1beta1.IssuanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			}, weekdays...),
+			now:          time.Date(2025, 6, 7, 23, 0, 0, 0, time.UTC),
+			expectChange: time.Date(2025, 6, 8, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows, err := newIssuanceWindows(tt.windows)
+			if err != nil {
+				t.Fatalf("newIssuanceWindows returned error: %v", err)
+			}
+			provisioner := &CertCentral{suspend: tt.suspend, issuanceWindows: windows}
+
+			suspension := provisioner.Suspension(tt.now)
+			if tt.expectReason == "" {
+				if suspension != nil {
+					t.Fatalf("expected no suspension, got %+v", suspension)
+				}
+			} else {
+				if suspension == nil || suspension.Reason != tt.expectReason {
+					t.Fatalf("expected suspension with reason %s, got %+v", tt.expectReason, suspension)
+				}
+				if !suspension.Until.Equal(tt.expectUntil) {
+					t.Fatalf("unexpected end of suspension, got=%s expected=%s", suspension.Until, tt.expectUntil)
+				}
+			}
+
+			if change := provisioner.NextIssuanceWindowChange(tt.now); !change.Equal(tt.expectChange) {
+				t.Fatalf("unexpected next issuance window change, got=%s expected=%s", change, tt.expectChange)
+			}
+		})
+	}
+}
+
+func TestValidateIssuanceWindows(t *testing.T) {
+	tests := []struct {
+		name      string
+		windows   []v1beta1.IssuanceWindow
+		expectErr bool
+	}{
+		{name: "not set"},
+		{name: "valid", windows: []v1beta1.IssuanceWindow{{Schedule: "@daily", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "America/New_York"}}},
+		{name: "invalid schedule", windows: []v1beta1.IssuanceWindow{{Schedule: "every day", Duration: metav1.Duration{Duration: time.Hour}}}, expectErr: true},
+		{name: "missing duration", windows: []v1beta1.IssuanceWindow{{Schedule: "0 8 * * *"}}, expectErr: true},
+		{name: "invalid time zone", windows: []v1beta1.IssuanceWindow{{Schedule: "0 8 * * *", Duration: metav1.Duration{Duration: time.Hour}, TimeZone: "Mars/Olympus"}}, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateIssuanceWindows(tt.windows); (err != nil) != tt.expectErr {
+				t.Fatalf("unexpected result, expected error=%t, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"context"
+	"testing"
+
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/sapcc/digicert-issuer/pkg/tracing"
+	certcentral "github.com/sapcc/go-certcentral"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCertCentralSignSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	fixture := buildChainFixture(t)
+	mock := &mockCertCentralClient{submitOrder: &certcentral.Order{ID: 42, CertificateID: 43, CertificateChain: constructCertificateChain(t, fixture.regularBundle)}}
+	provisioner := &CertCentral{
+		client:   &instrumentedClient{certCentralClient: mock, issuer: "default/tracing-test"},
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	cr := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: "tracing-test", Namespace: "default", UID: "5678"},
+		Spec:       certmanagerv1.CertificateRequestSpec{Request: createCSR(t, fixture.requestedCN)},
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "test")
+	if _, _, _, err := provisioner.Sign(ctx, cr, OrderOptions{}); err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+	parent.End()
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+
+	submit, ok := spans[endpointSubmitOrder]
+	if !ok {
+		t.Fatalf("expected span %q, got %v", endpointSubmitOrder, spans)
+	}
+	if submit.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("expected span %q to be a child of the calling span", endpointSubmitOrder)
+	}
+	expectAttribute(t, submit, tracing.AttributeOrderID.String("42"))
+	expectAttribute(t, submit, tracing.AttributeIssuer.String("default/tracing-test"))
+	expectAttribute(t, submit, attribute.String("http.response.status_code", "2xx"))
+
+	chain, ok := spans["CertCentral.buildPreferredChain"]
+	if !ok {
+		t.Fatalf("expected span for chain building, got %v", spans)
+	}
+	expectAttribute(t, chain, tracing.AttributeCertificateRequestUID.String("5678"))
+}
+
+func expectAttribute(t *testing.T, span tracetest.SpanStub, expected attribute.KeyValue) {
+	t.Helper()
+	for _, attr := range span.Attributes {
+		if attr.Key == expected.Key {
+			if attr.Value != expected.Value {
+				t.Fatalf("unexpected value of attribute %s on span %q, got=%v expected=%v", expected.Key, span.Name, attr.Value.Emit(), expected.Value.Emit())
+			}
+			return
+		}
+	}
+	t.Fatalf("expected attribute %s on span %q", expected.Key, span.Name)
+}
@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"sort"
+	"time"
+)
+
+var (
+	oidPKCS7Data       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS7SignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+)
+
+// TrustAnchors contains the distinct CA certificates of issued certificate chains.
+type TrustAnchors struct {
+	Roots         []*x509.Certificate
+	Intermediates []*x509.Certificate
+}
+
+// CollectTrustAnchors returns the roots and intermediates of the given certificate chains that are valid at the given
+// time. Chains are completed from the chain cache or via Authority Information Access, so roots are included even if
+// they were removed from the chain.
+func (c *CertCentral) CollectTrustAnchors(ctx context.Context, chains [][]*x509.Certificate, now time.Time) TrustAnchors {
+	var (
+		cas       []*x509.Certificate
+		completed = make(map[string]bool)
+	)
+	for _, chain := range chains {
+		bundle, _ := dedupeCertificates(chain)
+		for _, cert := range bundle {
+			if cert.IsCA {
+				cas = append(cas, cert)
+			}
+		}
+
+		// Chains of leaves with the same issuer only need to be completed once.
+		leaf := findLeaf(bundle)
+		if leaf == nil || completed[issuerFingerprint(leaf)] {
+			continue
+		}
+		completed[issuerFingerprint(leaf)] = true
+
+		path, ok := c.chainCache.get(leaf)
+		if !ok {
+			path, _, _ = c.completePath(ctx, leaf, bundle, "", "")
+			c.chainCache.add("", path)
+		}
+		cas = append(cas, path[1:]...)
+	}
+
+	var anchors TrustAnchors
+	cas, _ = dedupeCertificates(cas)
+	for _, cert := range cas {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			continue
+		}
+		if isSelfSigned(cert) {
+			anchors.Roots = append(anchors.Roots, cert)
+		} else {
+			anchors.Intermediates = append(anchors.Intermediates, cert)
+		}
+	}
+	sortCertificates(anchors.Roots)
+	sortCertificates(anchors.Intermediates)
+	return anchors
+}
+
+// All returns the roots followed by the intermediates.
+func (t TrustAnchors) All() []*x509.Certificate {
+	return append(append([]*x509.Certificate{}, t.Roots...), t.Intermediates...)
+}
+
+// sortCertificates orders the certificates by subject and validity, so the output is stable across reconciliations.
+func sortCertificates(certs []*x509.Certificate) {
+	sort.SliceStable(certs, func(i, j int) bool {
+		if c := bytes.Compare(certs[i].RawSubject, certs[j].RawSubject); c != 0 {
+			return c < 0
+		}
+		if !certs[i].NotBefore.Equal(certs[j].NotBefore) {
+			return certs[i].NotBefore.Before(certs[j].NotBefore)
+		}
+		return bytes.Compare(certs[i].Raw, certs[j].Raw) < 0
+	})
+}
+
+// EncodePEM returns the PEM encoded certificates.
+func EncodePEM(certs []*x509.Certificate) ([]byte, error) {
+	out := make([]byte, 0)
+	for _, cert := range certs {
+		crtPEM, err := encodeCertificate(cert)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, crtPEM...)
+	}
+	return out, nil
+}
+
+// EncodePKCS7 returns the certificates as DER encoded degenerate PKCS#7 SignedData without signers (RFC 2315),
+// the format commonly used with the .p7b extension.
+func EncodePKCS7(certs []*x509.Certificate) ([]byte, error) {
+	var raw []byte
+	for _, cert := range certs {
+		raw = append(raw, cert.Raw...)
+	}
+
+	emptySet := asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true}
+	signedData, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: emptySet,
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{ContentType: oidPKCS7Data},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: raw},
+		SignerInfos:      emptySet,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: oidPKCS7SignedData,
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+}
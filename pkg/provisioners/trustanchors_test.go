@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCertCentralCollectTrustAnchors(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+
+	leaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+	otherLeaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+	unrelatedLeaf := issueLeaf(t, fixture.csr, fixture.unrelatedRoot, fixture.unrelatedRootKey, nil)
+
+	cache := NewChainCache(DefaultChainCacheSize)
+	cache.add("", []*x509.Certificate{leaf, fixture.intermediate, fixture.root})
+	provisioner := &CertCentral{recorder: record.NewFakeRecorder(10), chainCache: cache}
+
+	anchors := provisioner.CollectTrustAnchors(context.Background(), [][]*x509.Certificate{
+		{leaf, fixture.intermediate},
+		{otherLeaf, fixture.intermediate},
+		{unrelatedLeaf, fixture.unrelatedRoot},
+	}, time.Now())
+
+	roots := certCNs(anchors.Roots)
+	sort.Strings(roots)
+	if expected := []string{"Unrelated Root", "Verify Root"}; !reflect.DeepEqual(roots, expected) {
+		t.Fatalf("expected roots %v, got %v", expected, roots)
+	}
+	if got, expected := certCNs(anchors.Intermediates), []string{"Verify Intermediate"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected intermediates %v, got %v", expected, got)
+	}
+
+	// Expired CA certificates are not published.
+	anchors = provisioner.CollectTrustAnchors(context.Background(), [][]*x509.Certificate{{leaf, fixture.intermediate}}, time.Now().Add(2*365*24*time.Hour))
+	if len(anchors.All()) != 0 {
+		t.Fatalf("expected expired certificates to be removed, got %v", certCNs(anchors.All()))
+	}
+}
+
+func TestCertCentralCollectTrustAnchorsAIA(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(fixture.root.Raw)
+	}))
+	defer server.Close()
+
+	// The root is removed from the chain, e.g. via --disable-root-ca, and fetched via AIA.
+	intermediate := generateCert(t, certSpec{cn: "Verify Intermediate", isCA: true, aia: []string{server.URL}}, fixture.root, fixture.rootKey, fixture.intermediateKey)
+	leaf := issueLeaf(t, fixture.csr, intermediate, fixture.intermediateKey, nil)
+
+	provisioner := &CertCentral{recorder: record.NewFakeRecorder(10), aiaClient: server.Client()}
+	anchors := provisioner.CollectTrustAnchors(context.Background(), [][]*x509.Certificate{{leaf, intermediate}}, time.Now())
+
+	if got, expected := certCNs(anchors.Roots), []string{"Verify Root"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected roots %v, got %v", expected, got)
+	}
+}
+
+func TestEncodePKCS7(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+	certs := []*x509.Certificate{fixture.root, fixture.intermediate}
+
+	der, err := EncodePKCS7(certs)
+	if err != nil {
+		t.Fatalf("EncodePKCS7 returned error: %v", err)
+	}
+
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(der, &contentInfo); err != nil {
+		t.Fatalf("failed to decode content info: %v", err)
+	}
+	if !contentInfo.ContentType.Equal(oidPKCS7SignedData) {
+		t.Fatalf("expected signed data content type, got %v", contentInfo.ContentType)
+	}
+
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue `asn1:"tag:0"`
+		SignerInfos      asn1.RawValue
+	}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		t.Fatalf("failed to decode signed data: %v", err)
+	}
+
+	decoded, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificates: %v", err)
+	}
+	if len(decoded) != len(certs) {
+		t.Fatalf("expected %d certificates, got %d", len(certs), len(decoded))
+	}
+	for idx := range certs {
+		if !bytes.Equal(decoded[idx].Raw, certs[idx].Raw) {
+			t.Fatalf("certificate %d does not match", idx)
+		}
+	}
+}
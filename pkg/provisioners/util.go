@@ -20,12 +20,22 @@ limitations under the License.
 package provisioners
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 
+	"github.com/hashicorp/go-multierror"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
 	certcentral "github.com/sapcc/go-certcentral"
 )
 
+// signatureHashAuto selects the signature hash based on the key of the certificate request.
+const signatureHashAuto = "auto"
+
 func mapToOrderType(s string) (certcentral.OrderType, bool) {
 	orderTypes := listAvailableOrderTypes()
 	for _, t := range orderTypes {
@@ -63,3 +73,93 @@ func listAvailablePaymentMethods() []certcentral.PaymentMethod {
 	}
 	return paymentMethods
 }
+
+func mapToSignatureHash(s string) (certcentral.SignatureHash, bool) {
+	signatureHashes := listAvailableSignatureHashes()
+	for _, h := range signatureHashes {
+		if strings.ToLower(s) == strings.ToLower(h.String()) {
+			return h, true
+		}
+	}
+	return "", false
+}
+
+func listAvailableSignatureHashes() []certcentral.SignatureHash {
+	var signatureHashes []certcentral.SignatureHash
+	v := reflect.ValueOf(certcentral.SignatureHashes)
+	for i := 0; i < v.NumField(); i++ {
+		signatureHashes = append(signatureHashes, v.Field(i).Interface().(certcentral.SignatureHash))
+	}
+	return signatureHashes
+}
+
+func mapToServerPlatformType(s string) (certcentral.ServerPlatformType, bool) {
+	v := reflect.ValueOf(certcentral.ServerPlatformTypes)
+	for i := 0; i < v.NumField(); i++ {
+		if strings.ToLower(s) == strings.ToLower(v.Type().Field(i).Name) {
+			return v.Field(i).Interface().(certcentral.ServerPlatformType), true
+		}
+	}
+	return 0, false
+}
+
+// signatureHashForKey returns the signature hash matching the strength of the public key of the certificate request.
+func signatureHashForKey(certReq *x509.CertificateRequest) certcentral.SignatureHash {
+	pub, ok := certReq.PublicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return certcentral.SignatureHashes.SHA256
+	}
+
+	switch pub.Curve {
+	case elliptic.P384():
+		return certcentral.SignatureHashes.SHA384
+	case elliptic.P521():
+		return certcentral.SignatureHashes.SHA512
+	default:
+		return certcentral.SignatureHashes.SHA256
+	}
+}
+
+func mapToPublicKeyAlgorithm(s string) (x509.PublicKeyAlgorithm, bool) {
+	for _, a := range []x509.PublicKeyAlgorithm{x509.RSA, x509.ECDSA, x509.Ed25519} {
+		if strings.ToLower(s) == strings.ToLower(a.String()) {
+			return a, true
+		}
+	}
+	return x509.UnknownPublicKeyAlgorithm, false
+}
+
+// ValidateCertificateSettings validates the signature hash, server platform and CA selection of the provisioner spec.
+func ValidateCertificateSettings(provisionerSpec v1beta1.DigicertProvisioner) error {
+	var errs error
+
+	if h := provisionerSpec.SignatureHash; h != "" && !strings.EqualFold(h, signatureHashAuto) {
+		if _, ok := mapToSignatureHash(h); !ok {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.signatureHash %q is not supported", h))
+		}
+	}
+	if p := provisionerSpec.ServerPlatform; p != "" {
+		if _, ok := mapToServerPlatformType(p); !ok {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.serverPlatform %q is not supported", p))
+		}
+	}
+	if strings.EqualFold(provisionerSpec.SignatureHash, certcentral.SignatureHashes.SHA1.String()) {
+		errs = multierror.Append(errs, errors.New("spec.provisioner.signatureHash sha1 is not allowed"))
+	}
+	for idx, sel := range provisionerSpec.CACertIDsByKey {
+		if _, ok := mapToPublicKeyAlgorithm(sel.KeyAlgorithm); !ok {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.caCertIDsByKey[%d].keyAlgorithm %q is not supported", idx, sel.KeyAlgorithm))
+		}
+		if sel.CACertID == "" {
+			errs = multierror.Append(errs, fmt.Errorf("spec.provisioner.caCertIDsByKey[%d].caCertID missing", idx))
+		}
+	}
+	if err := validateChainPreferences(provisionerSpec); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+	if err := validateAllowedIssuerFingerprints(provisionerSpec); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	return errs
+}
@@ -20,10 +20,15 @@ limitations under the License.
 package provisioners
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/sapcc/digicert-issuer/apis/certmanager/v1beta1"
 	certcentral "github.com/sapcc/go-certcentral"
 )
 
@@ -59,3 +64,56 @@ var _ = Describe("Map Order Type", func() {
 		Expect(found).To(BeFalse())
 	})
 })
+
+var _ = Describe("Map Signature Hash", func() {
+	It("should map existing signature hash string to correct signature hash", func() {
+		signatureHash, found := mapToSignatureHash("SHA384")
+		Expect(signatureHash).To(Equal(certcentral.SignatureHashes.SHA384))
+		Expect(found).To(BeTrue())
+	})
+
+	It("should return false for non-existing signature hash string", func() {
+		signatureHash, found := mapToSignatureHash("md5")
+		Expect(signatureHash).To(BeEmpty())
+		Expect(found).To(BeFalse())
+	})
+
+	It("should select the signature hash based on the ECDSA curve", func() {
+		for curve, expected := range map[elliptic.Curve]certcentral.SignatureHash{
+			elliptic.P256(): certcentral.SignatureHashes.SHA256,
+			elliptic.P384(): certcentral.SignatureHashes.SHA384,
+			elliptic.P521(): certcentral.SignatureHashes.SHA512,
+		} {
+			key, err := ecdsa.GenerateKey(curve, rand.Reader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(signatureHashForKey(&x509.CertificateRequest{PublicKey: &key.PublicKey})).To(Equal(expected))
+		}
+	})
+})
+
+var _ = Describe("Map Server Platform", func() {
+	It("should map existing server platform string to correct server platform", func() {
+		serverPlatform, found := mapToServerPlatformType("other")
+		Expect(serverPlatform).To(Equal(certcentral.ServerPlatformTypes.Other))
+		Expect(found).To(BeTrue())
+	})
+
+	It("should return false for non-existing server platform string", func() {
+		serverPlatform, found := mapToServerPlatformType("iis")
+		Expect(serverPlatform).To(BeZero())
+		Expect(found).To(BeFalse())
+	})
+})
+
+var _ = Describe("Validate Certificate Settings", func() {
+	It("should accept supported values", func() {
+		Expect(ValidateCertificateSettings(v1beta1.DigicertProvisioner{SignatureHash: "auto", ServerPlatform: "nginx"})).To(Succeed())
+		Expect(ValidateCertificateSettings(v1beta1.DigicertProvisioner{SignatureHash: "sha384"})).To(Succeed())
+	})
+
+	It("should reject unsupported values", func() {
+		Expect(ValidateCertificateSettings(v1beta1.DigicertProvisioner{SignatureHash: "md5"})).NotTo(Succeed())
+		Expect(ValidateCertificateSettings(v1beta1.DigicertProvisioner{SignatureHash: "sha1"})).NotTo(Succeed())
+		Expect(ValidateCertificateSettings(v1beta1.DigicertProvisioner{ServerPlatform: "iis"})).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// allowedClockSkew is the tolerated difference between the local clock and the validity of an issued certificate.
+const allowedClockSkew = 5 * time.Minute
+
+// VerifyCertificate checks that the PEM encoded certificate chain was issued for the PEM encoded certificate request.
+// The leaf certificate must contain the public key and all subject alternative names of the request, be valid at the
+// given time and chain up to the root certificates in caPEM or, if not given, the last certificate of the chain.
+func VerifyCertificate(csrPEM, certPEM, caPEM []byte, now time.Time) error {
+	certReq, err := decodeCertificateRequest(csrPEM)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate request: %w", err)
+	}
+
+	chain, err := DecodeCertificates(certPEM)
+	if err != nil {
+		return fmt.Errorf("failed to decode certificate chain: %w", err)
+	}
+	roots, err := DecodeCertificates(caPEM)
+	if err != nil {
+		return fmt.Errorf("failed to decode CA certificates: %w", err)
+	}
+
+	leaf := findLeaf(chain)
+	if leaf == nil {
+		return errors.New("leaf certificate not found in chain")
+	}
+
+	var errs error
+	if pub, ok := leaf.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(certReq.PublicKey) {
+		errs = multierror.Append(errs, errors.New("public key of the certificate does not match the certificate request"))
+	}
+	for _, name := range missingSubjectAltNames(certReq, leaf) {
+		errs = multierror.Append(errs, fmt.Errorf("certificate does not contain requested name %s", name))
+	}
+	if now.Add(allowedClockSkew).Before(leaf.NotBefore) {
+		errs = multierror.Append(errs, fmt.Errorf("certificate is not valid before %s", leaf.NotBefore.UTC().Format(time.RFC3339)))
+	}
+	if now.After(leaf.NotAfter) {
+		errs = multierror.Append(errs, fmt.Errorf("certificate expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	if err := verifyChain(leaf, chain, roots, now); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	return errs
+}
+
+// verifyChain verifies the leaf against the roots using the remaining certificates of the chain as intermediates.
+// If no roots are given, the last certificate of the chain is used as trust anchor.
+func verifyChain(leaf *x509.Certificate, chain, roots []*x509.Certificate, now time.Time) error {
+	intermediates := make([]*x509.Certificate, 0, len(chain))
+	for _, cert := range chain {
+		if !bytes.Equal(cert.Raw, leaf.Raw) {
+			intermediates = append(intermediates, cert)
+		}
+	}
+
+	if len(roots) == 0 {
+		if len(intermediates) == 0 {
+			return errors.New("certificate chain does not contain any CA certificate")
+		}
+		roots = intermediates[len(intermediates)-1:]
+		intermediates = intermediates[:len(intermediates)-1]
+	}
+
+	rootPool := x509.NewCertPool()
+	for _, cert := range roots {
+		rootPool.AddCert(cert)
+	}
+	intermediatePool := x509.NewCertPool()
+	for _, cert := range intermediates {
+		intermediatePool.AddCert(cert)
+	}
+
+	// Only the validity of the leaf is checked with the allowed clock skew above, so the chain is verified
+	// at a time the leaf is valid at.
+	verifyTime := now
+	if verifyTime.Before(leaf.NotBefore) {
+		verifyTime = leaf.NotBefore
+	}
+	if verifyTime.After(leaf.NotAfter) {
+		verifyTime = leaf.NotAfter
+	}
+
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         rootPool,
+		Intermediates: intermediatePool,
+		CurrentTime:   verifyTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("certificate chain does not verify: %w", err)
+	}
+	return nil
+}
+
+// missingSubjectAltNames returns the names of the certificate request not contained in the certificate.
+func missingSubjectAltNames(certReq *x509.CertificateRequest, cert *x509.Certificate) []string {
+	var missing []string
+
+	dnsNames := make(map[string]bool, len(cert.DNSNames))
+	for _, name := range cert.DNSNames {
+		dnsNames[strings.ToLower(name)] = true
+	}
+	for _, name := range certReq.DNSNames {
+		if !dnsNames[strings.ToLower(name)] {
+			missing = append(missing, "DNS:"+name)
+		}
+	}
+
+	for _, reqIP := range certReq.IPAddresses {
+		found := false
+		for _, ip := range cert.IPAddresses {
+			if ip.Equal(reqIP) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, "IP:"+reqIP.String())
+		}
+	}
+
+	if cn := certReq.Subject.CommonName; cn != "" && !strings.EqualFold(cn, cert.Subject.CommonName) && !dnsNames[strings.ToLower(cn)] {
+		missing = append(missing, "CN:"+cn)
+	}
+
+	return missing
+}
+
+// DecodeCertificates decodes the PEM encoded certificates.
+func DecodeCertificates(data []byte) ([]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0)
+	for len(bytes.TrimSpace(data)) > 0 {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			return nil, errors.New("failed to decode certificate pem")
+		}
+		data = rest
+
+		if block.Type != blockTypeCertificate {
+			return nil, fmt.Errorf("pem is not of type %s, but: %s", blockTypeCertificate, block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
@@ -0,0 +1,199 @@
+// SPDX-FileCopyrightText: 2025 SAP SE or an SAP affiliate company and sapcc contributors
+// SPDX-License-Identifier: Apache-2.0
+
+package provisioners
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+)
+
+type verifyFixture struct {
+	csrPEM           []byte
+	csr              *x509.CertificateRequest
+	root             *x509.Certificate
+	rootKey          *rsa.PrivateKey
+	intermediate     *x509.Certificate
+	intermediateKey  *rsa.PrivateKey
+	unrelatedRoot    *x509.Certificate
+	unrelatedRootKey *rsa.PrivateKey
+}
+
+func buildVerifyFixture(t *testing.T) verifyFixture {
+	t.Helper()
+
+	rootKey := generateRSAKey(t)
+	root := generateCert(t, certSpec{cn: "Verify Root", isCA: true}, nil, nil, rootKey)
+	intermediateKey := generateRSAKey(t)
+	intermediate := generateCert(t, certSpec{cn: "Verify Intermediate", isCA: true}, root, rootKey, intermediateKey)
+	unrelatedRootKey := generateRSAKey(t)
+	unrelatedRoot := generateCert(t, certSpec{cn: "Unrelated Root", isCA: true}, nil, nil, unrelatedRootKey)
+
+	key := generateRSAKey(t)
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "verify.test.local"},
+		DNSNames:    []string{"verify.test.local", "www.verify.test.local"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+	}, key)
+	if err != nil {
+		t.Fatalf("create csr: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	if err != nil {
+		t.Fatalf("parse csr: %v", err)
+	}
+
+	return verifyFixture{
+		csrPEM:           pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificateRequest, Bytes: csrDER}),
+		csr:              csr,
+		root:             root,
+		rootKey:          rootKey,
+		intermediate:     intermediate,
+		intermediateKey:  intermediateKey,
+		unrelatedRoot:    unrelatedRoot,
+		unrelatedRootKey: unrelatedRootKey,
+	}
+}
+
+// issueLeaf issues a leaf certificate for the certificate request. The template can be modified before signing.
+func issueLeaf(t *testing.T, csr *x509.CertificateRequest, parent *x509.Certificate, parentKey *rsa.PrivateKey, modify func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+
+	now := time.Now().UTC()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(now.UnixNano()),
+		Subject:               csr.Subject,
+		DNSNames:              csr.DNSNames,
+		IPAddresses:           csr.IPAddresses,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	publicKey := csr.PublicKey
+	if modify != nil {
+		modify(tmpl)
+		if tmpl.PublicKey != nil {
+			publicKey = tmpl.PublicKey
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, publicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create leaf certificate: %v", err)
+	}
+	crt, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse leaf certificate: %v", err)
+	}
+	return crt
+}
+
+func encodeCertificates(t *testing.T, certs ...*x509.Certificate) []byte {
+	t.Helper()
+	out := make([]byte, 0)
+	for _, cert := range certs {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: blockTypeCertificate, Bytes: cert.Raw})...)
+	}
+	return out
+}
+
+func TestVerifyCertificate(t *testing.T) {
+	fixture := buildVerifyFixture(t)
+
+	validLeaf := issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, nil)
+
+	tests := []struct {
+		name      string
+		certPEM   []byte
+		caPEM     []byte
+		expectErr bool
+	}{
+		{
+			name:    "valid_with_root",
+			certPEM: encodeCertificates(t, validLeaf, fixture.intermediate),
+			caPEM:   encodeCertificates(t, fixture.root),
+		},
+		{
+			name:    "valid_without_root",
+			certPEM: encodeCertificates(t, validLeaf, fixture.intermediate),
+		},
+		{
+			name:      "leaf_only",
+			certPEM:   encodeCertificates(t, validLeaf),
+			expectErr: true,
+		},
+		{
+			name: "public_key_mismatch",
+			certPEM: encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+				crt.PublicKey = &generateRSAKey(t).PublicKey
+			}), fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.root),
+			expectErr: true,
+		},
+		{
+			name: "missing_dns_name",
+			certPEM: encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+				crt.DNSNames = []string{"verify.test.local"}
+			}), fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.root),
+			expectErr: true,
+		},
+		{
+			name: "missing_ip_address",
+			certPEM: encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+				crt.IPAddresses = nil
+			}), fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.root),
+			expectErr: true,
+		},
+		{
+			name: "expired",
+			certPEM: encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+				crt.NotBefore = time.Now().Add(-48 * time.Hour)
+				crt.NotAfter = time.Now().Add(-24 * time.Hour)
+			}), fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.root),
+			expectErr: true,
+		},
+		{
+			name: "not_yet_valid",
+			certPEM: encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.intermediate, fixture.intermediateKey, func(crt *x509.Certificate) {
+				crt.NotBefore = time.Now().Add(time.Hour)
+			}), fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.root),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted_root",
+			certPEM:   encodeCertificates(t, validLeaf, fixture.intermediate),
+			caPEM:     encodeCertificates(t, fixture.unrelatedRoot),
+			expectErr: true,
+		},
+		{
+			name:      "wrong_intermediate",
+			certPEM:   encodeCertificates(t, issueLeaf(t, fixture.csr, fixture.unrelatedRoot, fixture.unrelatedRootKey, nil), fixture.intermediate),
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyCertificate(fixture.csrPEM, tt.certPEM, tt.caPEM, time.Now())
+			if tt.expectErr && err == nil {
+				t.Fatal("expected error, got nil")
+			}
+			if !tt.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
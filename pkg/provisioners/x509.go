@@ -21,6 +21,9 @@ package provisioners
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"errors"
@@ -86,3 +89,17 @@ func findLeaf(certBundle []*x509.Certificate) *x509.Certificate {
 	}
 	return nil
 }
+
+// publicKeySize returns the size of the public key of the certificate request in bits.
+func publicKeySize(cr *x509.CertificateRequest) int {
+	switch pub := cr.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return pub.N.BitLen()
+	case *ecdsa.PublicKey:
+		return pub.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return len(pub) * 8
+	default:
+		return 0
+	}
+}